@@ -0,0 +1,133 @@
+package ants
+
+import (
+	"sync"
+	"time"
+)
+
+// AdaptiveConfig 自适应并发度的参数
+// 采用AIMD（加性增、乘性减）的方式调整worker数：
+// 每个窗口统计一次任务的平均耗时和错误率，超过阈值说明下游变慢或者异常，worker数乘以 Backoff 快速收缩；
+// 否则如果worker已经用满（有任务在排队），则worker数加1缓慢试探
+type AdaptiveConfig struct {
+	MinWorkers    int           // worker数下限，默认1
+	MaxWorkers    int           // worker数上限，默认为 New 时的 workers
+	TargetLatency time.Duration // 任务平均耗时（包括重试）的阈值，0表示不按耗时判断
+	MaxErrorRate  float64       // 任务错误率的阈值，取值(0, 1]，0表示不按错误率判断
+	Window        time.Duration // 调整周期，默认1秒
+	Backoff       float64       // 乘性减的系数，取值(0, 1)，默认0.75
+}
+
+// 自适应并发度的统计窗口
+type adaptiveLimiter struct {
+	conf AdaptiveConfig
+
+	mu      sync.Mutex
+	count   int
+	errs    int
+	latency time.Duration
+}
+
+func newAdaptiveLimiter(conf AdaptiveConfig, workers int) *adaptiveLimiter {
+	if conf.MinWorkers <= 0 {
+		conf.MinWorkers = 1
+	}
+	if conf.MaxWorkers < workers {
+		conf.MaxWorkers = workers
+	}
+	if conf.Window <= 0 {
+		conf.Window = time.Second
+	}
+	if conf.Backoff <= 0 || conf.Backoff >= 1 {
+		conf.Backoff = 0.75
+	}
+	return &adaptiveLimiter{conf: conf}
+}
+
+// 记录一次任务的执行结果
+func (a *adaptiveLimiter) observe(latency time.Duration, err error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.count++
+	a.latency += latency
+	if err != nil {
+		a.errs++
+	}
+}
+
+// 根据上一个窗口的统计，计算新的worker数，并开始新的窗口
+// saturated 表示窗口结束时worker已经用满，只有这种情况才值得扩容
+func (a *adaptiveLimiter) next(limit int, saturated bool) int {
+	a.mu.Lock()
+	count, errs, latency := a.count, a.errs, a.latency
+	a.count, a.errs, a.latency = 0, 0, 0
+	a.mu.Unlock()
+
+	overloaded := false
+	if count > 0 {
+		if a.conf.TargetLatency > 0 && latency/time.Duration(count) > a.conf.TargetLatency {
+			overloaded = true
+		}
+		if a.conf.MaxErrorRate > 0 && float64(errs)/float64(count) > a.conf.MaxErrorRate {
+			overloaded = true
+		}
+	}
+
+	switch {
+	case overloaded:
+		limit = int(float64(limit) * a.conf.Backoff)
+	case saturated:
+		limit++
+	}
+	if limit < a.conf.MinWorkers {
+		limit = a.conf.MinWorkers
+	}
+	if limit > a.conf.MaxWorkers {
+		limit = a.conf.MaxWorkers
+	}
+	return limit
+}
+
+// 自适应调整协程，每个窗口调整一次worker数，协程池关闭时退出
+func (p *goPool) adapt() {
+	ticker := time.NewTicker(p.adaptive.conf.Window)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-p.quit:
+			return
+		case <-ticker.C:
+			p.mu.Lock()
+			limit := p.conf.Workers
+			saturated := p.running >= limit && p.queue.size > 0
+			p.mu.Unlock()
+			if n := p.adaptive.next(limit, saturated); n != limit {
+				p.Tune(n)
+			}
+		}
+	}
+}
+
+// Tune 实现 GoPool 接口的 Tune 方法
+// 缩容时，已经在执行的任务不受影响，只是在running降下来之前不再分发新任务
+func (p *goPool) Tune(workers int) {
+	if workers <= 0 {
+		return
+	}
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if workers == p.conf.Workers {
+		return
+	}
+	p.conf.Workers = workers
+	p.pool.Tune(workers)
+	p.notifyNotFull()
+	p.cond.Broadcast()
+}
+
+// Workers 实现 GoPool 接口的 Workers 方法
+func (p *goPool) Workers() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.conf.Workers
+}
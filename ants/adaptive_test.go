@@ -0,0 +1,95 @@
+package ants
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func Test_goPool_Tune(t *testing.T) {
+	pool := New("mypool_tune", 1, 0, 10, WithQueueSize(-1))
+	var running, peak int32
+	var futures []Future
+	for i := 0; i < 6; i++ {
+		f, _ := pool.SubmitFuture(context.Background(), func(ctx context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(100 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+		futures = append(futures, f)
+	}
+	pool.Tune(3)
+	if pool.Workers() != 3 {
+		t.Errorf("workers:%v", pool.Workers())
+	}
+	for _, f := range futures {
+		f.Wait()
+	}
+	if peak != 3 {
+		t.Errorf("peak:%v", peak)
+	}
+}
+
+func Test_adaptiveLimiter(t *testing.T) {
+	a := newAdaptiveLimiter(AdaptiveConfig{
+		MinWorkers:    2,
+		MaxWorkers:    10,
+		TargetLatency: 100 * time.Millisecond,
+		MaxErrorRate:  0.5,
+	}, 4)
+
+	// 没有排队，不扩容
+	a.observe(10*time.Millisecond, nil)
+	if n := a.next(4, false); n != 4 {
+		t.Errorf("idle:%v", n)
+	}
+	// worker用满且下游健康，加1
+	a.observe(10*time.Millisecond, nil)
+	if n := a.next(4, true); n != 5 {
+		t.Errorf("grow:%v", n)
+	}
+	// 耗时超过阈值，乘性减
+	a.observe(300*time.Millisecond, nil)
+	if n := a.next(8, true); n != 6 {
+		t.Errorf("slow:%v", n)
+	}
+	// 错误率超过阈值，乘性减，但不低于下限
+	a.observe(10*time.Millisecond, errors.New("fail"))
+	if n := a.next(2, true); n != 2 {
+		t.Errorf("errors:%v", n)
+	}
+	// 不超过上限
+	if n := a.next(10, true); n != 10 {
+		t.Errorf("max:%v", n)
+	}
+}
+
+func Test_goPool_Adaptive(t *testing.T) {
+	pool := New("mypool_adaptive", 4, 0, 10, WithAdaptive(AdaptiveConfig{
+		MinWorkers:    1,
+		TargetLatency: 10 * time.Millisecond,
+		Window:        50 * time.Millisecond,
+	}))
+	defer pool.ShutdownNow()
+
+	// 下游变慢，worker数应当收缩
+	for i := 0; i < 20; i++ {
+		pool.Submit(context.Background(), func() error {
+			time.Sleep(30 * time.Millisecond)
+			return nil
+		})
+	}
+	time.Sleep(300 * time.Millisecond)
+	if pool.Workers() >= 4 {
+		t.Errorf("workers should shrink, now:%v", pool.Workers())
+	}
+}
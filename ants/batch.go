@@ -0,0 +1,142 @@
+package ants
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+)
+
+// ErrNotSlice Map、ForEach 的 items 参数不是slice
+var ErrNotSlice = errors.New("ants: items must be a slice")
+
+// Group 类似 errgroup.Group，任务跑在共享的 GoPool 上，第一个错误会取消组内其他任务
+// 可以给组单独设置并发上限，避免一个组占满整个协程池
+type Group struct {
+	pool   GoPool
+	ctx    context.Context
+	cancel context.CancelFunc
+	sem    chan struct{} // 组内并发上限，nil表示不限制（仍然受协程池限制）
+
+	wg      sync.WaitGroup
+	errOnce sync.Once
+	err     error
+}
+
+// NewGroup 新建任务组，limit<=0 表示组内不限制并发
+// 返回的 ctx 在组内第一个任务失败或者 Wait 返回之后被取消
+func NewGroup(ctx context.Context, pool GoPool, limit int) (*Group, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &Group{pool: pool, ctx: ctx, cancel: cancel}
+	if limit > 0 {
+		g.sem = make(chan struct{}, limit)
+	}
+	return g, ctx
+}
+
+// Go 提交任务，组内并发达到上限时阻塞，直到有任务结束或者组被取消
+func (g *Group) Go(fn func(ctx context.Context) error) {
+	if g.sem != nil {
+		select {
+		case g.sem <- struct{}{}:
+		case <-g.ctx.Done():
+			return
+		}
+	}
+	g.wg.Add(1)
+	f, err := g.pool.SubmitFuture(g.ctx, fn)
+	if err != nil {
+		g.done(err)
+		return
+	}
+	go func() {
+		g.done(f.Wait())
+	}()
+}
+
+func (g *Group) done(err error) {
+	if err != nil {
+		g.errOnce.Do(func() {
+			g.err = err
+			g.cancel()
+		})
+	}
+	if g.sem != nil {
+		<-g.sem
+	}
+	g.wg.Done()
+}
+
+// Wait 等待组内所有任务结束，返回第一个错误
+func (g *Group) Wait() error {
+	g.wg.Wait()
+	g.cancel()
+	return g.err
+}
+
+// Map 并发处理 items（必须是slice）中的每个元素，结果按输入顺序返回
+// 任意一个元素处理失败，会取消其他元素的处理，并返回第一个错误
+func Map(ctx context.Context, pool GoPool, items interface{},
+	fn func(ctx context.Context, item interface{}) (interface{}, error)) ([]interface{}, error) {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice {
+		return nil, ErrNotSlice
+	}
+	results := make([]interface{}, v.Len())
+	g, _ := NewGroup(ctx, pool, 0)
+	for i := 0; i < v.Len(); i++ {
+		i, item := i, v.Index(i).Interface()
+		g.Go(func(ctx context.Context) error {
+			r, err := fn(ctx, item)
+			if err != nil {
+				return err
+			}
+			results[i] = r
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		return nil, err
+	}
+	return results, nil
+}
+
+// ForEach 并发处理 items（必须是slice）中的每个元素
+// failFast 为true时，第一个错误会取消其他元素的处理；否则所有元素都会被处理，返回按输入顺序的第一个错误
+func ForEach(ctx context.Context, pool GoPool, items interface{},
+	fn func(ctx context.Context, item interface{}) error, failFast bool) error {
+	v := reflect.ValueOf(items)
+	if v.Kind() != reflect.Slice {
+		return ErrNotSlice
+	}
+	if failFast {
+		g, _ := NewGroup(ctx, pool, 0)
+		for i := 0; i < v.Len(); i++ {
+			item := v.Index(i).Interface()
+			g.Go(func(ctx context.Context) error {
+				return fn(ctx, item)
+			})
+		}
+		return g.Wait()
+	}
+
+	errs := make([]error, v.Len())
+	futures := make([]Future, v.Len())
+	for i := 0; i < v.Len(); i++ {
+		item := v.Index(i).Interface()
+		futures[i], errs[i] = pool.SubmitFuture(ctx, func(ctx context.Context) error {
+			return fn(ctx, item)
+		})
+	}
+	for i, f := range futures {
+		if f != nil {
+			errs[i] = f.Wait()
+		}
+	}
+	for _, err := range errs {
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,100 @@
+package ants
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestMap(t *testing.T) {
+	pool := New("mypool_map", 4, 0, 10)
+	items := []int{5, 4, 3, 2, 1}
+	results, err := Map(context.Background(), pool, items, func(ctx context.Context, item interface{}) (interface{}, error) {
+		n := item.(int)
+		time.Sleep(time.Duration(n) * 10 * time.Millisecond) // 越靠前的越晚结束
+		return n * n, nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, r := range results {
+		if r.(int) != items[i]*items[i] {
+			t.Errorf("results:%v", results)
+			break
+		}
+	}
+
+	if _, err := Map(context.Background(), pool, 1, nil); err != ErrNotSlice {
+		t.Errorf("err:%v", err)
+	}
+}
+
+func TestMapFailFast(t *testing.T) {
+	pool := New("mypool_map_fail", 2, 0, 10)
+	errBad := errors.New("bad item")
+	var finished int32
+	_, err := Map(context.Background(), pool, []int{0, 1, 2, 3, 4, 5}, func(ctx context.Context, item interface{}) (interface{}, error) {
+		if item.(int) == 0 {
+			return nil, errBad
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(time.Second):
+			atomic.AddInt32(&finished, 1)
+			return item, nil
+		}
+	})
+	if err != errBad {
+		t.Errorf("err:%v", err)
+	}
+	if finished != 0 {
+		t.Errorf("others should be canceled, finished:%v", finished)
+	}
+}
+
+func TestForEach(t *testing.T) {
+	pool := New("mypool_foreach", 2, 0, 10)
+	errBad := errors.New("bad item")
+	var sum int32
+	err := ForEach(context.Background(), pool, []int32{1, 2, 3, 4}, func(ctx context.Context, item interface{}) error {
+		if item.(int32) == 2 {
+			return errBad
+		}
+		atomic.AddInt32(&sum, item.(int32))
+		return nil
+	}, false)
+	// 不是fail-fast，其他元素照常处理
+	if err != errBad || sum != 8 {
+		t.Errorf("err:%v, sum:%v", err, sum)
+	}
+}
+
+func TestGroup(t *testing.T) {
+	pool := New("mypool_group", 10, 0, 10)
+	g, _ := NewGroup(context.Background(), pool, 2)
+	var running, peak int32
+	for i := 0; i < 6; i++ {
+		g.Go(func(ctx context.Context) error {
+			n := atomic.AddInt32(&running, 1)
+			for {
+				p := atomic.LoadInt32(&peak)
+				if n <= p || atomic.CompareAndSwapInt32(&peak, p, n) {
+					break
+				}
+			}
+			time.Sleep(20 * time.Millisecond)
+			atomic.AddInt32(&running, -1)
+			return nil
+		})
+	}
+	if err := g.Wait(); err != nil {
+		t.Fatal(err)
+	}
+	// 协程池有10个worker，但组内并发不超过2
+	if peak != 2 {
+		t.Errorf("peak:%v", peak)
+	}
+}
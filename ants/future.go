@@ -0,0 +1,76 @@
+package ants
+
+import (
+	"context"
+	"sync"
+)
+
+// Future 任务句柄，由 SubmitFuture 返回
+// 调用方不必再自己围绕协程池搭建 WaitGroup 和 error channel，通过句柄即可等待任务、获取结果
+type Future interface {
+	// Wait 阻塞等待任务结束（包括所有重试），返回最终的错误
+	Wait() error
+	// Done 任务结束后会被关闭，可以和 select 搭配使用
+	Done() <-chan struct{}
+	// Err 任务的最终错误，任务尚未结束时返回 nil
+	Err() error
+	// Attempts 任务实际执行的次数（首次执行 + 重试次数）
+	Attempts() int
+	// Cancel 取消任务，传递给 task 的 ctx 会被取消
+	Cancel()
+}
+
+type future struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	mu       sync.Mutex
+	err      error
+	attempts int
+}
+
+func newFuture(ctx context.Context) *future {
+	ctx, cancel := context.WithCancel(ctx)
+	return &future{
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+}
+
+// 任务结束，记录结果并唤醒所有等待者
+// 同时释放派生出来的 ctx，避免泄露
+func (f *future) finish(attempts int, err error) {
+	f.mu.Lock()
+	f.attempts = attempts
+	f.err = err
+	f.mu.Unlock()
+	f.cancel()
+	close(f.done)
+}
+
+func (f *future) Wait() error {
+	<-f.done
+	return f.Err()
+}
+
+func (f *future) Done() <-chan struct{} {
+	return f.done
+}
+
+func (f *future) Err() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.err
+}
+
+func (f *future) Attempts() int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.attempts
+}
+
+func (f *future) Cancel() {
+	f.cancel()
+}
@@ -0,0 +1,72 @@
+package ants
+
+import (
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+)
+
+func Test_goPool_SubmitFuture(t *testing.T) {
+	pool := New("mypool_future", 3, 2, 10)
+	ctx := context.Background()
+
+	// 成功的任务
+	f1, err := pool.SubmitFuture(ctx, func(ctx context.Context) error {
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// 一直失败的任务，会重试2次，共执行3次
+	errFail := errors.New("always fail")
+	f2, err := pool.SubmitFuture(ctx, func(ctx context.Context) error {
+		return errFail
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if err := f1.Wait(); err != nil {
+		t.Errorf("f1 err:%v", err)
+	}
+	if f1.Attempts() != 1 {
+		t.Errorf("f1 attempts:%v", f1.Attempts())
+	}
+	if err := f2.Wait(); err != errFail {
+		t.Errorf("f2 err:%v", err)
+	}
+	if f2.Attempts() != 3 {
+		t.Errorf("f2 attempts:%v", f2.Attempts())
+	}
+	log.Println("f1:", f1.Err(), f1.Attempts(), "f2:", f2.Err(), f2.Attempts())
+}
+
+func Test_goPool_SubmitFutureCancel(t *testing.T) {
+	pool := New("mypool_future_cancel", 1, 0, 10)
+	f, err := pool.SubmitFuture(context.Background(), func(ctx context.Context) error {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(5 * time.Second):
+			return nil
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(50 * time.Millisecond)
+	f.Cancel()
+
+	select {
+	case <-f.Done():
+	case <-time.After(time.Second):
+		t.Fatal("cancel not work")
+	}
+	if f.Err() != context.Canceled {
+		t.Errorf("err:%v", f.Err())
+	}
+}
@@ -0,0 +1,101 @@
+package ants
+
+import (
+	"time"
+
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+// 协程池的监控指标，基于go-metrics，默认注册在 go-metrics 的 DefaultRegistry 中（metrics 包的示例用的也是它）
+// 指标名统一以 ants.<name>. 为前缀：
+//
+//	workers     Gauge  当前的worker数
+//	running     Gauge  正在执行的任务数
+//	free        Gauge  空闲的worker数
+//	queued      Gauge  等待队列中的任务数
+//	submit_wait Timer  任务从入队到被分发给worker的等待时间
+//	latency     Timer  任务的执行耗时（包括重试）
+//	retries     Meter  重试次数
+//	failures    Meter  重试之后最终仍然失败的任务数
+//	panics      Meter  任务panic的次数
+//
+// 协程池关闭时注销这些指标，Meter、Timer 也随之停止
+type poolMetrics struct {
+	registry   gometrics.Registry
+	registered map[string]interface{} // 完整指标名 -> 本协程池注册的指标
+
+	submitWait gometrics.Timer
+	latency    gometrics.Timer
+	retries    gometrics.Meter
+	failures   gometrics.Meter
+	panics     gometrics.Meter
+}
+
+func newPoolMetrics(p *goPool, r gometrics.Registry) *poolMetrics {
+	prefix := "ants." + p.name + "."
+	m := &poolMetrics{
+		registry:   r,
+		registered: make(map[string]interface{}),
+		submitWait: gometrics.NewTimer(),
+		latency:    gometrics.NewTimer(),
+		retries:    gometrics.NewMeter(),
+		failures:   gometrics.NewMeter(),
+		panics:     gometrics.NewMeter(),
+	}
+	// 同名的协程池，指标以最后创建的为准
+	register := func(name string, metric interface{}) {
+		r.Unregister(prefix + name)
+		_ = r.Register(prefix+name, metric)
+		m.registered[prefix+name] = metric
+	}
+
+	register("workers", gometrics.NewFunctionalGauge(func() int64 {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return int64(p.conf.Workers)
+	}))
+	register("running", gometrics.NewFunctionalGauge(func() int64 {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return int64(p.running)
+	}))
+	register("free", gometrics.NewFunctionalGauge(func() int64 {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		if p.running >= p.conf.Workers { // 缩容之后running可能暂时超过worker数
+			return 0
+		}
+		return int64(p.conf.Workers - p.running)
+	}))
+	register("queued", gometrics.NewFunctionalGauge(func() int64 {
+		p.mu.Lock()
+		defer p.mu.Unlock()
+		return int64(p.queue.size)
+	}))
+	register("submit_wait", m.submitWait)
+	register("latency", m.latency)
+	register("retries", m.retries)
+	register("failures", m.failures)
+	register("panics", m.panics)
+	return m
+}
+
+// 注销本协程池的指标，已经被后创建的同名协程池替换掉的不动
+func (m *poolMetrics) unregister() {
+	for name, metric := range m.registered {
+		if m.registry.Get(name) == metric {
+			m.registry.Unregister(name)
+		}
+	}
+}
+
+// 记录一次任务执行的结果
+func (m *poolMetrics) observe(begin time.Time, attempts int, err error) {
+	m.latency.UpdateSince(begin)
+	if attempts > 1 {
+		m.retries.Mark(int64(attempts - 1))
+	}
+	if err != nil {
+		m.failures.Mark(1)
+	}
+}
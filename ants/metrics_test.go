@@ -0,0 +1,83 @@
+package ants
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+func Test_goPool_Metrics(t *testing.T) {
+	r := gometrics.NewRegistry()
+	pool := New("mypool_metrics", 2, 1, 1, WithQueueSize(1), WithMetricsRegistry(r))
+	ctx := context.Background()
+
+	block := make(chan struct{})
+	var futures []Future
+	for i := 0; i < 3; i++ {
+		f, _ := pool.SubmitFuture(ctx, func(ctx context.Context) error {
+			<-block
+			return nil
+		})
+		futures = append(futures, f)
+	}
+	time.Sleep(20 * time.Millisecond)
+
+	gauge := func(name string) int64 {
+		return r.Get("ants.mypool_metrics." + name).(gometrics.Gauge).Value()
+	}
+	if gauge("running") != 2 || gauge("free") != 0 || gauge("queued") != 1 {
+		t.Errorf("running:%v, free:%v, queued:%v", gauge("running"), gauge("free"), gauge("queued"))
+	}
+	close(block)
+
+	f, _ := pool.SubmitFuture(ctx, func(ctx context.Context) error {
+		return errors.New("fail")
+	})
+	futures = append(futures, f)
+	for _, f := range futures {
+		f.Wait()
+	}
+
+	if n := r.Get("ants.mypool_metrics.latency").(gometrics.Timer).Count(); n != 4 {
+		t.Errorf("latency count:%v", n)
+	}
+	if n := r.Get("ants.mypool_metrics.submit_wait").(gometrics.Timer).Count(); n != 4 {
+		t.Errorf("submit_wait count:%v", n)
+	}
+	if n := r.Get("ants.mypool_metrics.retries").(gometrics.Meter).Count(); n != 1 {
+		t.Errorf("retries:%v", n)
+	}
+	if n := r.Get("ants.mypool_metrics.failures").(gometrics.Meter).Count(); n != 1 {
+		t.Errorf("failures:%v", n)
+	}
+}
+
+func Test_goPool_MetricsUnregister(t *testing.T) {
+	r := gometrics.NewRegistry()
+	old := New("mypool_metrics_unregister", 1, 0, 10, WithMetricsRegistry(r))
+	pool := New("mypool_metrics_unregister", 1, 0, 10, WithMetricsRegistry(r))
+	other := New("mypool_metrics_other", 1, 0, 10, WithMetricsRegistry(r))
+
+	// 被替换掉的同名协程池关闭时，不影响后创建的协程池的指标
+	old.ShutdownNow()
+	if r.Get("ants.mypool_metrics_unregister.latency") == nil {
+		t.Error("metrics of the newer pool unregistered")
+	}
+
+	if _, err := pool.Shutdown(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	r.Each(func(name string, _ interface{}) {
+		if strings.HasPrefix(name, "ants.mypool_metrics_unregister.") {
+			t.Errorf("metric %v not unregistered", name)
+		}
+	})
+	if r.Get("ants.mypool_metrics_other.latency") == nil {
+		t.Error("metrics of other pool unregistered")
+	}
+	other.ShutdownNow()
+}
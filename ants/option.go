@@ -1,10 +1,21 @@
 package ants
 
+import "github.com/rcrowley/go-metrics"
+
 type Option func(opts *Options)
 
 type Options struct {
     SubmitNonBlock  bool      // 当协程池满了之后，如何处理，默认是阻塞处理，即会一直等待
-    SubmitRetryIntervalMs int // 当协程池满了之后，如果是非阻塞，则定期重新尝试submit
+    SubmitRetryIntervalMs int // 当协程池满了之后，如果是非阻塞，则定期重新尝试submit，默认10ms；需要直接拒绝的请使用 OverflowReject
+    QueueSize int             // 等待队列的长度，默认0表示不额外排队，worker全忙时提交即阻塞；小于0表示不限制
+    OverflowPolicy OverflowPolicy // 等待队列满了之后的处理策略，默认 OverflowBlock
+    OnOverflow func(name string, policy OverflowPolicy) // 等待队列满的时候回调，可以用来告警
+    RetryPolicy RetryPolicy   // 任务失败之后的重试策略，默认按照 New 的 retryIntervalMs 固定间隔重试
+    IsRetryable func(err error) bool // 错误分类，返回false则不再重试，默认是 IsRetryable
+    TenantWeights map[string]int // 租户的调度权重，未设置的租户权重为1
+    MetricsRegistry metrics.Registry // 监控指标注册的位置，默认是 go-metrics 的 DefaultRegistry
+    Adaptive *AdaptiveConfig  // 自适应并发度，默认不开启，worker数固定
+    Logger Logger             // 协程池的日志输出，默认是标准库的log
 }
 
 func reloadOptions(options ...Option) *Options {
@@ -25,4 +36,99 @@ func WithSubmitRetryIntervalMs(interval int) Option {
     return func(opts *Options) {
         opts.SubmitRetryIntervalMs = interval
     }
-}
\ No newline at end of file
+}
+
+func WithQueueSize(size int) Option {
+    return func(opts *Options) {
+        opts.QueueSize = size
+    }
+}
+
+func WithOverflowPolicy(policy OverflowPolicy) Option {
+    return func(opts *Options) {
+        opts.OverflowPolicy = policy
+    }
+}
+
+func WithOnOverflow(onOverflow func(name string, policy OverflowPolicy)) Option {
+    return func(opts *Options) {
+        opts.OnOverflow = onOverflow
+    }
+}
+
+func WithRetryPolicy(policy RetryPolicy) Option {
+    return func(opts *Options) {
+        opts.RetryPolicy = policy
+    }
+}
+
+func WithIsRetryable(isRetryable func(err error) bool) Option {
+    return func(opts *Options) {
+        opts.IsRetryable = isRetryable
+    }
+}
+
+// 按租户设置调度权重，未设置的租户权重为1
+// 同一优先级下，各租户按权重比例分享worker，例如 {"online": 3, "batch": 1}
+func WithTenantWeights(weights map[string]int) Option {
+    return func(opts *Options) {
+        opts.TenantWeights = weights
+    }
+}
+
+func WithMetricsRegistry(registry metrics.Registry) Option {
+    return func(opts *Options) {
+        opts.MetricsRegistry = registry
+    }
+}
+
+// 开启自适应并发度，根据任务耗时和错误率自动增减worker，适合调用下游服务的协程池
+func WithAdaptive(conf AdaptiveConfig) Option {
+    return func(opts *Options) {
+        opts.Adaptive = &conf
+    }
+}
+
+func WithLogger(logger Logger) Option {
+    return func(opts *Options) {
+        opts.Logger = logger
+    }
+}
+
+type SubmitOption func(opts *SubmitOptions)
+
+// 单个任务提交时的参数
+type SubmitOptions struct {
+    Priority Priority // 任务优先级，默认 PriorityNormal
+    Tenant   string   // 任务所属租户，默认 DefaultTenant
+    nonBlock bool     // 队列满时不阻塞、也不在调用方协程中执行，直接返回 ErrPoolOverload，给定时器等不能被卡住的调用方用
+}
+
+func loadSubmitOptions(options ...SubmitOption) *SubmitOptions {
+    opts := &SubmitOptions{Priority: PriorityNormal, Tenant: DefaultTenant}
+    for _, option := range options {
+        option(opts)
+    }
+    if opts.Priority < PriorityHigh || opts.Priority > PriorityLow {
+        opts.Priority = PriorityNormal
+    }
+    return opts
+}
+
+func WithPriority(priority Priority) SubmitOption {
+    return func(opts *SubmitOptions) {
+        opts.Priority = priority
+    }
+}
+
+func WithTenant(tenant string) SubmitOption {
+    return func(opts *SubmitOptions) {
+        opts.Tenant = tenant
+    }
+}
+
+func withNonBlock() SubmitOption {
+    return func(opts *SubmitOptions) {
+        opts.nonBlock = true
+    }
+}
@@ -0,0 +1,89 @@
+package ants
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+var (
+	// ErrPoolOverload 等待队列已满，任务被拒绝（OverflowReject）
+	ErrPoolOverload = errors.New("ants: too many tasks waiting in queue")
+	// ErrTaskDropped 任务在排队时被新任务挤掉了（OverflowDropOldest）
+	ErrTaskDropped = errors.New("ants: task dropped from queue")
+)
+
+// OverflowPolicy 等待队列满了之后，新提交的任务如何处理
+type OverflowPolicy int
+
+const (
+	OverflowBlock      OverflowPolicy = iota + 1 // 阻塞等待队列腾出位置，直到提交时传入的 ctx 到期
+	OverflowReject                               // 直接拒绝，返回 ErrPoolOverload
+	OverflowDropOldest                           // 丢弃队列中最老的任务（优先从低优先级中丢弃），被丢弃的任务以 ErrTaskDropped 结束
+	OverflowCallerRuns                           // 在调用方的协程中直接执行，相当于给提交方施加反压
+)
+
+func (o OverflowPolicy) String() string {
+	switch o {
+	case OverflowBlock:
+		return "block"
+	case OverflowReject:
+		return "reject"
+	case OverflowDropOldest:
+		return "drop_oldest"
+	case OverflowCallerRuns:
+		return "caller_runs"
+	}
+	return "unknown"
+}
+
+// 队列是否已满，调用时持有 p.mu
+// QueueSize 为0时不额外排队，worker全忙就算满，和ants默认的阻塞提交一致；小于0表示不限制
+func (p *goPool) queueFull() bool {
+	switch {
+	case p.conf.QueueSize > 0:
+		return p.queue.size >= p.conf.QueueSize
+	case p.conf.QueueSize < 0:
+		return false
+	}
+	return p.running+p.queue.size >= p.conf.Workers
+}
+
+// 出队、worker空闲或者扩容之后，唤醒因为队列满而阻塞的提交者，调用时持有 p.mu
+func (p *goPool) notifyNotFull() {
+	if p.notFull != nil {
+		close(p.notFull)
+		p.notFull = nil
+	}
+}
+
+// 阻塞等待队列腾出位置，调用时持有 p.mu，返回时仍然持有
+func (p *goPool) waitNotFull(ctx context.Context) error {
+	if p.notFull == nil {
+		p.notFull = make(chan struct{})
+	}
+	notFull := p.notFull
+	p.mu.Unlock()
+	defer p.mu.Lock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-notFull:
+		return nil
+	}
+}
+
+// 设置了 SubmitNonBlock 时，不在 notFull 上等待，而是每隔 SubmitRetryIntervalMs 重新尝试一次
+// 调用时持有 p.mu，返回时仍然持有
+func (p *goPool) waitRetry(ctx context.Context) error {
+	timer := time.NewTimer(p.conf.SubmitRetryIntervalMs)
+	defer timer.Stop()
+	p.mu.Unlock()
+	defer p.mu.Lock()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
@@ -0,0 +1,176 @@
+package ants
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// 占住唯一的worker，并把长度为1的等待队列塞满，返回放行的函数
+func fillPool(t *testing.T, pool GoPool) (Future, func()) {
+	block := make(chan struct{})
+	pool.Submit(context.Background(), func() error {
+		<-block
+		return nil
+	})
+	time.Sleep(10 * time.Millisecond)
+	queued, err := pool.SubmitFuture(context.Background(), func(ctx context.Context) error {
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return queued, func() { close(block) }
+}
+
+func Test_goPool_DefaultBlock(t *testing.T) {
+	pool := New("mypool_default_block", 1, 0, 10)
+	block := make(chan struct{})
+	pool.Submit(context.Background(), func() error {
+		<-block
+		return nil
+	})
+
+	// 未设置 QueueSize 时不额外排队，唯一的worker被占住之后，提交会阻塞到ctx到期
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pool.Submit(ctx, func() error { return nil }); err != context.DeadlineExceeded {
+		t.Errorf("err:%v", err)
+	}
+	if st := pool.QueueStats(); st.Total != 0 {
+		t.Errorf("stats:%+v", st)
+	}
+
+	// worker空闲之后，阻塞的提交者被唤醒
+	done := make(chan error, 1)
+	go func() {
+		done <- pool.Submit(context.Background(), func() error { return nil })
+	}()
+	time.Sleep(10 * time.Millisecond)
+	close(block)
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("err:%v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("submit still blocked after worker released")
+	}
+}
+
+func Test_goPool_OverflowReject(t *testing.T) {
+	var alerts int32
+	pool := New("mypool_overflow_reject", 1, 0, 10,
+		WithQueueSize(1),
+		WithOverflowPolicy(OverflowReject),
+		WithOnOverflow(func(name string, policy OverflowPolicy) {
+			atomic.AddInt32(&alerts, 1)
+		}))
+	_, unblock := fillPool(t, pool)
+	defer unblock()
+
+	if err := pool.Submit(context.Background(), func() error { return nil }); err != ErrPoolOverload {
+		t.Errorf("err:%v", err)
+	}
+	if atomic.LoadInt32(&alerts) != 1 {
+		t.Errorf("alerts:%v", alerts)
+	}
+}
+
+func Test_goPool_OverflowBlock(t *testing.T) {
+	pool := New("mypool_overflow_block", 1, 0, 10, WithQueueSize(1))
+	_, unblock := fillPool(t, pool)
+
+	// 阻塞到 ctx 到期
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if _, err := pool.SubmitFuture(ctx, func(ctx context.Context) error { return nil }); err != context.DeadlineExceeded {
+		t.Errorf("err:%v", err)
+	}
+
+	// 队列腾出位置之后，阻塞的提交者可以继续
+	go func() {
+		time.Sleep(50 * time.Millisecond)
+		unblock()
+	}()
+	f, err := pool.SubmitFuture(context.Background(), func(ctx context.Context) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Wait(); err != nil {
+		t.Errorf("err:%v", err)
+	}
+}
+
+func Test_goPool_OverflowDropOldest(t *testing.T) {
+	pool := New("mypool_overflow_drop", 1, 0, 10,
+		WithQueueSize(1),
+		WithOverflowPolicy(OverflowDropOldest))
+	queued, unblock := fillPool(t, pool)
+
+	f, err := pool.SubmitFuture(context.Background(), func(ctx context.Context) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := queued.Wait(); err != ErrTaskDropped {
+		t.Errorf("err:%v", err)
+	}
+	unblock()
+	if err := f.Wait(); err != nil {
+		t.Errorf("err:%v", err)
+	}
+}
+
+func Test_goPool_OverflowDropOldestNoQueue(t *testing.T) {
+	pool := New("mypool_overflow_drop_noqueue", 1, 0, 10,
+		WithOverflowPolicy(OverflowDropOldest))
+	block := make(chan struct{})
+	pool.Submit(context.Background(), func() error {
+		<-block
+		return nil
+	})
+
+	time.Sleep(10 * time.Millisecond)
+
+	// 默认不额外排队，没有可丢弃的任务，阻塞到 ctx 到期
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	if err := pool.Submit(ctx, func() error { return nil }); err != context.DeadlineExceeded {
+		t.Errorf("err:%v", err)
+	}
+
+	close(block)
+	f, err := pool.SubmitFuture(context.Background(), func(ctx context.Context) error { return nil })
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := f.Wait(); err != nil {
+		t.Errorf("err:%v", err)
+	}
+}
+
+func Test_goPool_OverflowCallerRuns(t *testing.T) {
+	pool := New("mypool_overflow_caller", 1, 0, 10,
+		WithQueueSize(1),
+		WithOverflowPolicy(OverflowCallerRuns))
+	_, unblock := fillPool(t, pool)
+	defer unblock()
+
+	ran := false
+	f, err := pool.SubmitFuture(context.Background(), func(ctx context.Context) error {
+		ran = true // 在当前协程中同步执行
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ran {
+		t.Errorf("task should run in caller goroutine")
+	}
+	select {
+	case <-f.Done():
+	default:
+		t.Errorf("future should be done")
+	}
+}
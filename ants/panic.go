@@ -0,0 +1,40 @@
+package ants
+
+import (
+	"context"
+	"errors"
+
+	pkgerrors "github.com/pkg/errors"
+)
+
+// ErrTaskPanic 任务发生了panic，panic会被recover并转换为包裹了该错误的error，附带调用栈
+// 可以用 errors.Is(err, ErrTaskPanic) 判断，用 %+v 打印出panic现场的调用栈
+var ErrTaskPanic = errors.New("ants: task panic")
+
+// Logger 协程池的日志接口，和ants库的Logger接口一致，标准库的 *log.Logger 可以直接使用
+type Logger interface {
+	Printf(format string, args ...interface{})
+}
+
+// LoggerFunc 函数形式的Logger，方便适配其他日志库，比如本项目的logger包：
+//
+//	ants.WithLogger(ants.LoggerFunc(func(format string, args ...interface{}) {
+//		logger.Error(format, args...)
+//	}))
+type LoggerFunc func(format string, args ...interface{})
+
+func (f LoggerFunc) Printf(format string, args ...interface{}) {
+	f(format, args...)
+}
+
+// 执行一次任务，任务内的panic被转换为error，和普通错误一样走重试、上报的流程
+// recover发生在panic的现场之上，所以此时记录的调用栈包含了panic的位置
+func (p *goPool) call(ctx context.Context, task func(ctx context.Context) error) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			p.metrics.panics.Mark(1)
+			err = pkgerrors.Wrapf(ErrTaskPanic, "%v", r)
+		}
+	}()
+	return task(ctx)
+}
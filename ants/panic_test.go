@@ -0,0 +1,68 @@
+package ants
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+)
+
+type bufLogger struct {
+	mu   sync.Mutex
+	logs []string
+}
+
+func (l *bufLogger) Printf(format string, args ...interface{}) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.logs = append(l.logs, fmt.Sprintf(format, args...))
+}
+
+func (l *bufLogger) String() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return strings.Join(l.logs, "\n")
+}
+
+func Test_goPool_Panic(t *testing.T) {
+	logger := &bufLogger{}
+	pool := New("mypool_panic", 1, 1, 10, WithLogger(logger))
+
+	// panic之后会和普通错误一样重试
+	var times int32
+	f, _ := pool.SubmitFuture(context.Background(), func(ctx context.Context) error {
+		if atomic.AddInt32(&times, 1) == 1 {
+			panic("first time panic")
+		}
+		return nil
+	})
+	if err := f.Wait(); err != nil || f.Attempts() != 2 {
+		t.Errorf("err:%v, attempts:%v", err, f.Attempts())
+	}
+
+	// 一直panic，最终的错误附带调用栈
+	f, _ = pool.SubmitFuture(context.Background(), func(ctx context.Context) error {
+		var m map[string]int
+		m["boom"] = 1
+		return nil
+	})
+	err := f.Wait()
+	if !errors.Is(err, ErrTaskPanic) {
+		t.Fatalf("err:%v", err)
+	}
+	if stack := fmt.Sprintf("%+v", err); !strings.Contains(stack, "panic_test.go") {
+		t.Errorf("stack not captured:%v", stack)
+	}
+
+	// 日志走注入的logger，并且协程池在panic之后仍然可用
+	if !strings.Contains(logger.String(), "AsyncTask[mypool_panic] execute error") {
+		t.Errorf("logs:%v", logger.String())
+	}
+	f, _ = pool.SubmitFuture(context.Background(), func(ctx context.Context) error { return nil })
+	if err := f.Wait(); err != nil {
+		t.Errorf("err:%v", err)
+	}
+}
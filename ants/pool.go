@@ -8,99 +8,322 @@ import (
 	"context"
 	"errors"
 	"github.com/panjf2000/ants/v2"
+	gometrics "github.com/rcrowley/go-metrics"
 	"log"
+	"sync"
 	"time"
 )
 
 type GoPool interface {
 	// Submit 加入任务，任务将异步并发执行，必须确保 task 之间是并发安全的
-	Submit(ctx context.Context, task func() error)
+	// 协程池关闭之后再提交，返回 ErrPoolClosed
+	// 可以通过 WithPriority、WithTenant 指定任务的优先级和租户
+	Submit(ctx context.Context, task func() error, opts ...SubmitOption) error
+	// SubmitFuture 加入任务并返回任务句柄，可以通过句柄等待任务、获取最终错误和执行次数，或者取消任务
+	// 传给 task 的 ctx 派生自参数 ctx，调用 Future.Cancel 之后会被取消
+	SubmitFuture(ctx context.Context, task func(ctx context.Context) error, opts ...SubmitOption) (Future, error)
+	// Shutdown 优雅关闭，不再接收新任务，并在 ctx 到期前等待在途任务（包括等待重试的任务）结束
+	Shutdown(ctx context.Context) (ShutdownReport, error)
+	// ShutdownNow 立即关闭，不再接收新任务，并取消所有在途任务的 ctx
+	ShutdownNow() ShutdownReport
+	// QueueStats 等待队列的深度，按优先级和租户分别统计
+	QueueStats() QueueStats
+	// Tune 运行时调整worker数，开启了自适应模式时，之后仍会被自适应逻辑继续调整
+	Tune(workers int)
+	// Workers 当前的worker数
+	Workers() int
 }
 
 type conf struct {
-	Workers         int `json:"workers"`
-	Retries         int `json:"retries"`
-	RetryIntervalMs time.Duration `json:"retry_interval_ms"`
-	SubmitNonBlock  bool `json:"submit_non_block"`   // 当协程池满了之后，如何处理，默认是阻塞处理，即会一直等待
-	SubmitRetryIntervalMs time.Duration `json:"retry_interval_ms"` // 当协程池满了之后，如果是非阻塞，则定期重新尝试submit
+	Workers               int            `json:"workers"`
+	Retries               int            `json:"retries"`
+	RetryIntervalMs       time.Duration  `json:"retry_interval_ms"`
+	QueueSize             int            `json:"queue_size"`               // 等待队列的长度，0表示不额外排队，worker全忙时提交即阻塞，小于0表示不限制
+	OverflowPolicy        OverflowPolicy `json:"overflow_policy"`          // 等待队列满了之后的处理策略，默认阻塞等待
+	SubmitNonBlock        bool           `json:"submit_non_block"`         // 当协程池满了之后，如何处理，默认是阻塞处理，即会一直等待
+	SubmitRetryIntervalMs time.Duration  `json:"submit_retry_interval_ms"` // 当协程池满了之后，如果是非阻塞，则定期重新尝试submit
 }
 
 type goPool struct {
-	name string
-	pool *ants.Pool
-	conf *conf
+	name        string
+	pool        *ants.Pool
+	conf        *conf
+	retryPolicy RetryPolicy
+	isRetryable func(err error) bool
+
+	mu       sync.Mutex
+	cond     *sync.Cond // 有新任务入队或者有worker空闲时，唤醒调度协程
+	queue    *waitQueue // 等待队列，任务先入队，再由调度协程按优先级、租户权重分发给worker
+	running  int        // 已经分发给worker的任务数，不超过 conf.Workers
+	closing  bool
+	inflight map[*future]struct{} // 在途任务，关闭时用来等待或者取消
+	drained  chan struct{}        // 关闭过程中，在途任务全部结束时被关闭
+	notFull  chan struct{}        // 队列满时阻塞的提交者在此等待，出队时被关闭
+
+	onOverflow func(name string, policy OverflowPolicy)
+	metrics    *poolMetrics
+	adaptive   *adaptiveLimiter // 自适应并发度，未开启时为nil
+	quit       chan struct{}    // 协程池关闭时被关闭，通知后台协程退出
+	logger     Logger
 }
 
 // New 新建协程池
 func New(name string, workers, retries, retryIntervalMs int, opts ...Option) GoPool {
 	paramOptions := reloadOptions(opts...)
-	var antsOpts []ants.Option
+	overflowPolicy := paramOptions.OverflowPolicy
+	if overflowPolicy == 0 { // SubmitNonBlock 也是阻塞到提交成功为止，只是改为定期重试
+		overflowPolicy = OverflowBlock
+	}
 	submitRetry := time.Duration(paramOptions.SubmitRetryIntervalMs) * time.Millisecond
-	if paramOptions.SubmitNonBlock {
-		antsOpts = append(antsOpts, ants.WithNonblocking(true))
-		if paramOptions.SubmitRetryIntervalMs == 0 {
-			submitRetry = 10 * time.Millisecond
-		}
+	if paramOptions.SubmitNonBlock && submitRetry == 0 {
+		submitRetry = 10 * time.Millisecond
 	}
-	pool, err := ants.NewPool(workers, antsOpts...)
-	if err != nil {
-		return nil
+	retryPolicy := paramOptions.RetryPolicy
+	if retryPolicy == nil {
+		retryPolicy = FixedBackoff(time.Duration(retryIntervalMs) * time.Millisecond)
+	}
+	isRetryable := paramOptions.IsRetryable
+	if isRetryable == nil {
+		isRetryable = IsRetryable
 	}
-	return &goPool{
-		pool: pool,
-		name: name,
+	logger := paramOptions.Logger
+	if logger == nil {
+		logger = LoggerFunc(log.Printf)
+	}
+	registry := paramOptions.MetricsRegistry
+	if registry == nil {
+		registry = gometrics.DefaultRegistry
+	}
+	p := &goPool{
+		name:        name,
+		retryPolicy: retryPolicy,
+		isRetryable: isRetryable,
+		queue:       newWaitQueue(paramOptions.TenantWeights),
+		inflight:    make(map[*future]struct{}),
+		onOverflow:  paramOptions.OnOverflow,
+		quit:        make(chan struct{}),
+		logger:      logger,
 		conf: &conf{
-			Workers:         workers,
-			Retries:         retries,
-			RetryIntervalMs: time.Duration(retryIntervalMs)*time.Millisecond,
-			SubmitNonBlock: paramOptions.SubmitNonBlock,
+			Workers:               workers,
+			Retries:               retries,
+			RetryIntervalMs:       time.Duration(retryIntervalMs) * time.Millisecond,
+			QueueSize:             paramOptions.QueueSize,
+			OverflowPolicy:        overflowPolicy,
+			SubmitNonBlock:        paramOptions.SubmitNonBlock,
 			SubmitRetryIntervalMs: submitRetry,
-	}}
+		}}
+	// 任务由调度协程在有空闲worker时才交给ants，所以ants本身用默认的阻塞模式即可
+	// 任务的panic在 call 中已经被recover，不会再交给ants处理
+	pool, err := ants.NewPool(workers, ants.WithLogger(p.logger))
+	if err != nil {
+		return nil
+	}
+	p.pool = pool
+	p.cond = sync.NewCond(&p.mu)
+	p.metrics = newPoolMetrics(p, registry)
+	go p.dispatch()
+	if paramOptions.Adaptive != nil {
+		p.adaptive = newAdaptiveLimiter(*paramOptions.Adaptive, workers)
+		go p.adapt()
+	}
+	return p
 }
 
 // Submit 实现 GoPool 接口的 Submit 方法
-// 这里submit实现了同步阻塞的提交方式，如果pool没有设置submitNonBlock，则天然阻塞
-// 如果设置了submitNonBlock，则这里通过自旋等待的方式，实现了阻塞，留出一个口子可以做一点其他事情
-func (p *goPool) Submit(ctx context.Context, task func() error) {
+// 这里submit实现了同步阻塞的提交方式，默认不额外排队，worker全忙时阻塞，直到有worker空闲或者ctx到期
+// 设置了 QueueSize 时，任务先进入等待队列，然后由调度协程在有空闲worker时，按照优先级、租户权重依次分发
+// 如果设置了submitNonBlock，则通过定期重试的方式实现阻塞，而不是直接拒绝；需要直接拒绝的请使用 OverflowReject
+func (p *goPool) Submit(ctx context.Context, task func() error, opts ...SubmitOption) error {
+	_, err := p.SubmitFuture(ctx, func(context.Context) error {
+		return task()
+	}, opts...)
+	if err != nil {
+		p.logger.Printf("AsyncTask[%v] submit error:%v", p.name, err)
+	}
+	return err
+}
+
+// SubmitFuture 实现 GoPool 接口的 SubmitFuture 方法，提交方式同 Submit
+// 如果等待队列已满，则按照 OverflowPolicy 处理
+func (p *goPool) SubmitFuture(ctx context.Context, task func(ctx context.Context) error, opts ...SubmitOption) (Future, error) {
+	so := loadSubmitOptions(opts...)
+	f := newFuture(ctx)
+	notified := false
+	p.mu.Lock()
 	for {
-		err := p.pool.Submit(func() {
-			err := tryDo(
-				ctx,
-				task,
-				p.conf.Retries,
-				p.conf.RetryIntervalMs)
-			if err != nil {
-				log.Printf("AsyncTask[%v] execute error:%v", p.name, err)
-			}
-		})
-		// 当设置了submitNonBlock，且协程池满了之后，会出现ErrPoolOverload错误，则sleep等待
-		if err != nil && errors.Is(err, ants.ErrPoolOverload) {
-			time.Sleep(p.conf.SubmitRetryIntervalMs)
-			// TODO 可以做一点其他事情
-			//log.Println("Have no enough goroutine. wait a little time")
+		if p.closing {
+			p.mu.Unlock()
+			f.finish(0, ErrPoolClosed)
+			return nil, ErrPoolClosed
+		}
+		if !p.queueFull() {
+			break
+		}
+		if !notified && p.onOverflow != nil { // 每次提交最多告警一次
+			notified = true
+			p.mu.Unlock()
+			p.onOverflow(p.name, p.conf.OverflowPolicy)
+			p.mu.Lock()
 			continue
 		}
-		if err != nil {
-			log.Printf("AsyncTask[%v] submit error:%v", p.name, err)
+
+		policy := p.conf.OverflowPolicy
+		if policy == OverflowDropOldest && p.queue.size == 0 { // 不额外排队时worker全忙就算满，但队列里没有可丢弃的任务，退化为阻塞等待
+			policy = OverflowBlock
 		}
+		if so.nonBlock && (policy == OverflowBlock || policy == OverflowCallerRuns) {
+			policy = OverflowReject
+		}
+		switch policy {
+		case OverflowReject:
+			p.mu.Unlock()
+			f.finish(0, ErrPoolOverload)
+			return nil, ErrPoolOverload
+		case OverflowDropOldest:
+			dropped := p.queue.dropOldest()
+			p.forget(dropped.f)
+			dropped.f.finish(0, ErrTaskDropped)
+			p.notifyNotFull()
+		case OverflowCallerRuns:
+			p.inflight[f] = struct{}{}
+			p.mu.Unlock()
+			p.run(f, task)
+			p.mu.Lock()
+			p.forget(f)
+			p.mu.Unlock()
+			return f, nil
+		default: // OverflowBlock
+			wait := p.waitNotFull
+			if p.conf.SubmitNonBlock {
+				wait = p.waitRetry
+			}
+			if err := wait(ctx); err != nil {
+				p.mu.Unlock()
+				f.finish(0, err)
+				return nil, err
+			}
+		}
+	}
+
+	p.inflight[f] = struct{}{}
+	p.queue.push(&queuedTask{
+		f:        f,
+		fn:       task,
+		priority: so.Priority,
+		tenant:   so.Tenant,
+		enqueued: time.Now(),
+	})
+	p.cond.Signal()
+	p.mu.Unlock()
+	return f, nil
+}
+
+// QueueStats 实现 GoPool 接口的 QueueStats 方法
+func (p *goPool) QueueStats() QueueStats {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.queue.stats()
+}
+
+// 调度协程：有空闲worker并且队列不空时，取出下一个任务分发给worker
+// 协程池关闭，并且队列已经清空时退出
+func (p *goPool) dispatch() {
+	for {
+		p.mu.Lock()
+		for p.queue.size == 0 || p.running >= p.conf.Workers {
+			if p.closing && p.queue.size == 0 {
+				p.mu.Unlock()
+				return
+			}
+			p.cond.Wait()
+		}
+		t := p.queue.pop()
+		p.running++
+		p.notifyNotFull()
+		p.mu.Unlock()
+		p.metrics.submitWait.UpdateSince(t.enqueued)
+		p.execute(t)
+	}
+}
+
+// 将任务交给ants执行
+// 排队期间已经被取消的任务，直接结束，不再占用worker
+func (p *goPool) execute(t *queuedTask) {
+	if err := t.f.ctx.Err(); err != nil {
+		t.f.finish(0, err)
+		p.release(t.f)
 		return
 	}
+	err := p.pool.Submit(func() {
+		p.run(t.f, t.fn)
+		p.release(t.f)
+	})
+	if err != nil {
+		if errors.Is(err, ants.ErrPoolClosed) {
+			err = ErrPoolClosed
+		}
+		t.f.finish(0, err)
+		p.release(t.f)
+	}
 }
 
-// 执行提交的任务，如果失败则按失败次数重试
-func tryDo(ctx context.Context, task func() error, times int, interval time.Duration) error {
+// 执行任务，并将结果回填到任务句柄中
+func (p *goPool) run(f *future, task func(ctx context.Context) error) {
+	begin := time.Now()
+	attempts, err := p.tryDo(f.ctx, task)
+	if err != nil { // %+v 可以打印出panic等错误附带的调用栈
+		p.logger.Printf("AsyncTask[%v] execute error:%+v", p.name, err)
+	}
+	p.metrics.observe(begin, attempts, err)
+	if p.adaptive != nil {
+		p.adaptive.observe(time.Since(begin), err)
+	}
+	f.finish(attempts, err)
+}
+
+// 任务结束，归还worker名额，并注销在途任务
+func (p *goPool) release(f *future) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.running--
+	p.forget(f)
+	p.notifyNotFull()
+	p.cond.Signal()
+}
+
+// 注销在途任务，调用时持有 p.mu
+// 关闭过程中最后一个任务结束时，通知 Shutdown
+func (p *goPool) forget(f *future) {
+	delete(p.inflight, f)
+	if p.closing && len(p.inflight) == 0 && p.drained != nil {
+		close(p.drained)
+		p.drained = nil
+	}
+}
+
+// 执行提交的任务，如果失败则按失败次数重试，返回实际执行次数和最终的错误
+// ctx 被取消之后，无论是正在等待重试还是准备下一次执行，都会立即放弃
+func (p *goPool) tryDo(ctx context.Context, task func(ctx context.Context) error) (int, error) {
 	i := 0
-	for  {
-		err := task()
-		if err == nil{
-			return nil
+	for {
+		if err := ctx.Err(); err != nil {
+			return i, err
 		}
-		log.Printf("task execute err:%v", err)
-		// ctx = context.Background()
-		if i >= times { // 放弃重试
-			return err
+		err := p.call(ctx, task)
+		if err == nil {
+			return i + 1, nil
+		}
+		p.logger.Printf("AsyncTask[%v] task execute err:%v", p.name, err)
+		if i >= p.conf.Retries || !p.isRetryable(err) { // 放弃重试
+			return i + 1, err
 		}
 		i++
-		time.Sleep(interval)
+		timer := time.NewTimer(p.retryPolicy.Backoff(i))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return i, ctx.Err()
+		case <-timer.C:
+		}
 	}
-}
\ No newline at end of file
+}
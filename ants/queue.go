@@ -0,0 +1,200 @@
+package ants
+
+import (
+	"context"
+	"time"
+)
+
+// Priority 任务优先级，高优先级的任务总是先于低优先级的任务被调度
+type Priority int
+
+const (
+	PriorityHigh   Priority = iota // 延迟敏感的任务，比如在线请求
+	PriorityNormal                 // 默认优先级
+	PriorityLow                    // 批处理等可以慢慢跑的任务
+
+	priorityLevels = 3
+)
+
+func (p Priority) String() string {
+	switch p {
+	case PriorityHigh:
+		return "high"
+	case PriorityNormal:
+		return "normal"
+	case PriorityLow:
+		return "low"
+	}
+	return "unknown"
+}
+
+// DefaultTenant 未指定租户的任务，都归属于这个租户
+const DefaultTenant = ""
+
+// QueueStats 等待队列的深度，按优先级和租户分别统计
+type QueueStats struct {
+	Total      int
+	ByPriority map[Priority]int
+	ByTenant   map[string]int
+}
+
+// 排队中的任务
+type queuedTask struct {
+	f        *future
+	fn       func(ctx context.Context) error
+	priority Priority
+	tenant   string
+	enqueued time.Time
+	seq      uint64 // 入队序号，越小越老
+}
+
+// 单个租户的任务队列，weight/current 用于平滑加权轮询
+type tenantQueue struct {
+	name    string
+	weight  int
+	current int
+	tasks   []*queuedTask
+}
+
+// 同一优先级的任务，按租户分开排队，租户之间按权重做平滑加权轮询（同nginx的smooth weighted round-robin）
+// 这样某个租户瞬间塞进大量任务，也只能按自己的权重占用worker，不会把其他租户饿死
+type lane struct {
+	tenants []*tenantQueue
+	index   map[string]*tenantQueue
+	size    int
+}
+
+func newLane() *lane {
+	return &lane{index: make(map[string]*tenantQueue)}
+}
+
+func (l *lane) push(t *queuedTask, weight int) {
+	q, ok := l.index[t.tenant]
+	if !ok {
+		q = &tenantQueue{name: t.tenant, weight: weight}
+		l.index[t.tenant] = q
+		l.tenants = append(l.tenants, q)
+	}
+	q.tasks = append(q.tasks, t)
+	l.size++
+}
+
+func (l *lane) pop() *queuedTask {
+	if l.size == 0 {
+		return nil
+	}
+	var best *tenantQueue
+	total := 0
+	for _, q := range l.tenants {
+		q.current += q.weight
+		total += q.weight
+		if best == nil || q.current > best.current {
+			best = q
+		}
+	}
+	best.current -= total
+	return l.take(best)
+}
+
+// 每个租户队列的队头就是该租户最老的任务，比较队头即可
+func (l *lane) dropOldest() *queuedTask {
+	var oldest *tenantQueue
+	for _, q := range l.tenants {
+		if oldest == nil || q.tasks[0].seq < oldest.tasks[0].seq {
+			oldest = q
+		}
+	}
+	if oldest == nil {
+		return nil
+	}
+	return l.take(oldest)
+}
+
+// 取出租户队列的队头，队列空了就摘掉，避免租户越积越多
+func (l *lane) take(q *tenantQueue) *queuedTask {
+	t := q.tasks[0]
+	q.tasks[0] = nil
+	q.tasks = q.tasks[1:]
+	l.size--
+	if len(q.tasks) == 0 {
+		l.remove(q)
+	}
+	return t
+}
+
+func (l *lane) remove(q *tenantQueue) {
+	delete(l.index, q.name)
+	for i, tq := range l.tenants {
+		if tq == q {
+			l.tenants = append(l.tenants[:i], l.tenants[i+1:]...)
+			break
+		}
+	}
+}
+
+// 等待队列：优先级之间严格按高低调度，同优先级内按租户加权轮询
+// 非并发安全，由 goPool.mu 保护
+type waitQueue struct {
+	lanes   [priorityLevels]*lane
+	weights map[string]int
+	size    int
+	seq     uint64
+}
+
+func newWaitQueue(weights map[string]int) *waitQueue {
+	s := &waitQueue{weights: weights}
+	for i := range s.lanes {
+		s.lanes[i] = newLane()
+	}
+	return s
+}
+
+func (s *waitQueue) weight(tenant string) int {
+	if w, ok := s.weights[tenant]; ok && w > 0 {
+		return w
+	}
+	return 1
+}
+
+func (s *waitQueue) push(t *queuedTask) {
+	s.seq++
+	t.seq = s.seq
+	s.lanes[t.priority].push(t, s.weight(t.tenant))
+	s.size++
+}
+
+func (s *waitQueue) pop() *queuedTask {
+	for _, l := range s.lanes {
+		if t := l.pop(); t != nil {
+			s.size--
+			return t
+		}
+	}
+	return nil
+}
+
+// 丢弃最老的任务，从低优先级往高优先级找，避免为了低优先级的新任务丢掉高优先级的任务
+func (s *waitQueue) dropOldest() *queuedTask {
+	for i := len(s.lanes) - 1; i >= 0; i-- {
+		if t := s.lanes[i].dropOldest(); t != nil {
+			s.size--
+			return t
+		}
+	}
+	return nil
+}
+
+func (s *waitQueue) stats() QueueStats {
+	st := QueueStats{
+		Total:      s.size,
+		ByPriority: make(map[Priority]int, priorityLevels),
+		ByTenant:   make(map[string]int),
+	}
+	for i, l := range s.lanes {
+		st.ByPriority[Priority(i)] = l.size
+		for _, q := range l.tenants {
+			st.ByTenant[q.name] += len(q.tasks)
+		}
+	}
+	return st
+}
@@ -0,0 +1,79 @@
+package ants
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func Test_waitQueue(t *testing.T) {
+	q := newWaitQueue(map[string]int{"a": 2, "b": 1})
+	for i := 0; i < 4; i++ {
+		q.push(&queuedTask{priority: PriorityLow, tenant: "a"})
+		q.push(&queuedTask{priority: PriorityLow, tenant: "b"})
+	}
+	q.push(&queuedTask{priority: PriorityHigh, tenant: "b"})
+
+	st := q.stats()
+	if st.Total != 9 || st.ByPriority[PriorityLow] != 8 || st.ByTenant["a"] != 4 || st.ByTenant["b"] != 5 {
+		t.Errorf("stats:%+v", st)
+	}
+
+	var order []string
+	for task := q.pop(); task != nil; task = q.pop() {
+		order = append(order, task.priority.String()+":"+task.tenant)
+	}
+	// 高优先级先出，同优先级内 a:b = 2:1，a 耗尽之后只剩 b
+	want := "high:b low:a low:b low:a low:a low:b low:a low:b low:b"
+	if got := strings.Join(order, " "); got != want {
+		t.Errorf("got:%v, want:%v", got, want)
+	}
+	if q.size != 0 {
+		t.Errorf("size:%v", q.size)
+	}
+}
+
+func Test_goPool_Priority(t *testing.T) {
+	pool := New("mypool_priority", 1, 0, 10, WithQueueSize(-1))
+	ctx := context.Background()
+
+	// 先占住唯一的worker，后面的任务都会排队
+	block := make(chan struct{})
+	pool.Submit(ctx, func() error {
+		<-block
+		return nil
+	})
+	time.Sleep(10 * time.Millisecond)
+
+	var mu sync.Mutex
+	var order []string
+	var futures []Future
+	submit := func(name string, opts ...SubmitOption) {
+		f, _ := pool.SubmitFuture(ctx, func(ctx context.Context) error {
+			mu.Lock()
+			order = append(order, name)
+			mu.Unlock()
+			return nil
+		}, opts...)
+		futures = append(futures, f)
+	}
+	submit("batch1", WithPriority(PriorityLow), WithTenant("batch"))
+	submit("batch2", WithPriority(PriorityLow), WithTenant("batch"))
+	submit("normal")
+	submit("online", WithPriority(PriorityHigh), WithTenant("online"))
+
+	st := pool.QueueStats()
+	if st.Total != 4 || st.ByPriority[PriorityLow] != 2 || st.ByTenant["batch"] != 2 {
+		t.Errorf("stats:%+v", st)
+	}
+
+	close(block)
+	for _, f := range futures {
+		f.Wait()
+	}
+	if got := strings.Join(order, " "); got != "online normal batch1 batch2" {
+		t.Errorf("order:%v", got)
+	}
+}
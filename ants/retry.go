@@ -0,0 +1,119 @@
+package ants
+
+import (
+	"context"
+	"errors"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+)
+
+// RetryPolicy 重试策略，决定第 attempt 次重试（从1开始计数）之前需要等待多久
+type RetryPolicy interface {
+	Backoff(attempt int) time.Duration
+}
+
+// RetryPolicyFunc 函数形式的重试策略
+type RetryPolicyFunc func(attempt int) time.Duration
+
+func (f RetryPolicyFunc) Backoff(attempt int) time.Duration {
+	return f(attempt)
+}
+
+// FixedBackoff 固定间隔重试，等价于原来的 RetryIntervalMs
+func FixedBackoff(interval time.Duration) RetryPolicy {
+	return RetryPolicyFunc(func(int) time.Duration {
+		return interval
+	})
+}
+
+// ExponentialBackoff 指数退避：base * multiplier^(attempt-1)
+// jitter 取值 [0, 1]，表示在计算出的间隔上随机减去的最大比例，用来打散同一时刻大量任务的集中重试
+func ExponentialBackoff(base time.Duration, multiplier, jitter float64) RetryPolicy {
+	if multiplier < 1 {
+		multiplier = 1
+	}
+	if jitter < 0 {
+		jitter = 0
+	}
+	if jitter > 1 {
+		jitter = 1
+	}
+	return &exponentialBackoff{
+		base:       base,
+		multiplier: multiplier,
+		jitter:     jitter,
+		rnd:        rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+type exponentialBackoff struct {
+	base       time.Duration
+	multiplier float64
+	jitter     float64
+
+	mu  sync.Mutex // rand.Rand 不是并发安全的
+	rnd *rand.Rand
+}
+
+func (b *exponentialBackoff) Backoff(attempt int) time.Duration {
+	if attempt < 1 {
+		attempt = 1
+	}
+	d := float64(b.base) * math.Pow(b.multiplier, float64(attempt-1))
+	if d > math.MaxInt64 { // 防止溢出
+		d = math.MaxInt64
+	}
+	if b.jitter > 0 {
+		b.mu.Lock()
+		d -= d * b.jitter * b.rnd.Float64()
+		b.mu.Unlock()
+	}
+	return time.Duration(d)
+}
+
+// CappedBackoff 给任意重试策略加上上限，通常和 ExponentialBackoff 搭配使用
+func CappedBackoff(policy RetryPolicy, max time.Duration) RetryPolicy {
+	return RetryPolicyFunc(func(attempt int) time.Duration {
+		d := policy.Backoff(attempt)
+		if d > max {
+			return max
+		}
+		return d
+	})
+}
+
+// 标记为永久性错误，不再重试
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *permanentError) Unwrap() error {
+	return e.err
+}
+
+// Permanent 将 err 标记为永久性错误，默认的 IsRetryable 遇到它就会放弃重试
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// IsRetryable 默认的错误分类：被 Permanent 标记的错误，以及 ctx 取消、超时的错误，都不再重试
+// 可以通过 WithIsRetryable 替换为自己的分类规则
+func IsRetryable(err error) bool {
+	var pe *permanentError
+	if errors.As(err, &pe) {
+		return false
+	}
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return true
+}
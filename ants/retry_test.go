@@ -0,0 +1,71 @@
+package ants
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	fixed := FixedBackoff(10 * time.Millisecond)
+	if fixed.Backoff(1) != 10*time.Millisecond || fixed.Backoff(5) != 10*time.Millisecond {
+		t.Errorf("fixed backoff wrong")
+	}
+
+	exp := ExponentialBackoff(10*time.Millisecond, 2, 0)
+	for i, want := range []time.Duration{10, 20, 40, 80} {
+		if got := exp.Backoff(i + 1); got != want*time.Millisecond {
+			t.Errorf("exp backoff attempt:%v, got:%v, want:%v", i+1, got, want*time.Millisecond)
+		}
+	}
+
+	// 带抖动的间隔，落在 [d*(1-jitter), d] 之间
+	jitter := ExponentialBackoff(100*time.Millisecond, 2, 0.5)
+	for i := 0; i < 100; i++ {
+		if d := jitter.Backoff(2); d < 100*time.Millisecond || d > 200*time.Millisecond {
+			t.Errorf("jitter backoff out of range:%v", d)
+		}
+	}
+
+	capped := CappedBackoff(exp, 30*time.Millisecond)
+	if capped.Backoff(1) != 10*time.Millisecond || capped.Backoff(10) != 30*time.Millisecond {
+		t.Errorf("capped backoff wrong")
+	}
+}
+
+func Test_goPool_RetryPermanent(t *testing.T) {
+	pool := New("mypool_retry_permanent", 1, 5, 10)
+	errFatal := errors.New("fatal")
+	f, _ := pool.SubmitFuture(context.Background(), func(ctx context.Context) error {
+		return Permanent(errFatal)
+	})
+	if err := f.Wait(); !errors.Is(err, errFatal) {
+		t.Errorf("err:%v", err)
+	}
+	if f.Attempts() != 1 {
+		t.Errorf("permanent error should not retry, attempts:%v", f.Attempts())
+	}
+}
+
+func Test_goPool_RetryCancel(t *testing.T) {
+	// 重试间隔很长，取消之后应当立即返回，而不是傻等
+	pool := New("mypool_retry_cancel", 1, 5, 0,
+		WithRetryPolicy(FixedBackoff(10*time.Second)),
+		WithIsRetryable(func(err error) bool { return true }))
+	f, _ := pool.SubmitFuture(context.Background(), func(ctx context.Context) error {
+		return errors.New("retry me")
+	})
+	time.Sleep(50 * time.Millisecond)
+	begin := time.Now()
+	f.Cancel()
+	if err := f.Wait(); err != context.Canceled {
+		t.Errorf("err:%v", err)
+	}
+	if time.Since(begin) > time.Second {
+		t.Errorf("cancel too slow:%v", time.Since(begin))
+	}
+	if f.Attempts() != 1 {
+		t.Errorf("attempts:%v", f.Attempts())
+	}
+}
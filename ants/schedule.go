@@ -0,0 +1,292 @@
+package ants
+
+import (
+	"container/heap"
+	"context"
+	"errors"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+var (
+	// ErrSchedulerStopped 定时器已经停止，不再接收新的定时任务
+	ErrSchedulerStopped = errors.New("ants: scheduler stopped")
+	// ErrInvalidInterval 周期任务的间隔必须大于0
+	ErrInvalidInterval = errors.New("ants: interval must be positive")
+)
+
+// Scheduler 基于 GoPool 的延时、周期任务
+// 所有定时任务放在一个小顶堆中，只用一个协程按到期时间依次唤醒，而不是每个定时器一个协程
+// 到期之后任务提交到 GoPool 中执行，并发度、重试、监控都沿用协程池的配置
+type Scheduler struct {
+	pool GoPool
+
+	mu      sync.Mutex
+	timers  timerHeap
+	seq     uint64
+	stopped bool
+	wake    chan struct{} // 堆顶发生变化时，唤醒调度协程重新计算等待时间
+	quit    chan struct{}
+}
+
+// ScheduledTask 定时任务的句柄
+type ScheduledTask struct {
+	s        *Scheduler
+	id       uint64
+	ctx      context.Context
+	task     func(ctx context.Context) error
+	opts     []SubmitOption
+	next     time.Time
+	interval time.Duration // 0表示只执行一次
+	index    int           // 在堆中的下标，-1表示不在堆中
+
+	running int32 // 周期任务上一次执行是否还没结束
+	runs    int64
+	skips   int64
+}
+
+// ScheduledInfo 定时任务的状态，用于查看
+type ScheduledInfo struct {
+	ID       uint64
+	Next     time.Time     // 下一次执行时间
+	Interval time.Duration // 执行间隔，0表示只执行一次
+	Runs     int64         // 已经提交执行的次数
+	Skips    int64         // 周期任务因为上一次执行还没结束，或者协程池已满而跳过的次数；一次性任务提交失败（协程池关闭、ctx结束）也计入
+}
+
+// NewScheduler 在协程池之上创建定时器
+func NewScheduler(pool GoPool) *Scheduler {
+	s := &Scheduler{
+		pool: pool,
+		wake: make(chan struct{}, 1),
+		quit: make(chan struct{}),
+	}
+	go s.loop()
+	return s
+}
+
+// SubmitAfter 延时 d 之后执行任务，ctx 被取消则任务不再执行
+func (s *Scheduler) SubmitAfter(ctx context.Context, d time.Duration, task func(ctx context.Context) error,
+	opts ...SubmitOption) (*ScheduledTask, error) {
+	return s.schedule(ctx, time.Now().Add(d), 0, task, opts)
+}
+
+// SubmitAt 在时间点 at 执行任务，ctx 被取消则任务不再执行
+func (s *Scheduler) SubmitAt(ctx context.Context, at time.Time, task func(ctx context.Context) error,
+	opts ...SubmitOption) (*ScheduledTask, error) {
+	return s.schedule(ctx, at, 0, task, opts)
+}
+
+// Every 每隔 interval 执行一次任务，直到 ctx 被取消或者调用 Cancel
+// 如果上一次执行还没有结束，则跳过本次，而不是堆积起来
+func (s *Scheduler) Every(ctx context.Context, interval time.Duration, task func(ctx context.Context) error,
+	opts ...SubmitOption) (*ScheduledTask, error) {
+	if interval <= 0 {
+		return nil, ErrInvalidInterval
+	}
+	return s.schedule(ctx, time.Now().Add(interval), interval, task, opts)
+}
+
+func (s *Scheduler) schedule(ctx context.Context, at time.Time, interval time.Duration,
+	task func(ctx context.Context) error, opts []SubmitOption) (*ScheduledTask, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return nil, ErrSchedulerStopped
+	}
+	s.seq++
+	t := &ScheduledTask{
+		s:        s,
+		id:       s.seq,
+		ctx:      ctx,
+		task:     task,
+		opts:     opts,
+		next:     at,
+		interval: interval,
+	}
+	heap.Push(&s.timers, t)
+	if t.index == 0 {
+		s.notify()
+	}
+	return t, nil
+}
+
+// Scheduled 列出还在等待执行的定时任务，按下一次执行时间排序
+func (s *Scheduler) Scheduled() []ScheduledInfo {
+	s.mu.Lock()
+	infos := make([]ScheduledInfo, 0, len(s.timers))
+	for _, t := range s.timers {
+		if t.ctx.Err() == nil {
+			infos = append(infos, t.info())
+		}
+	}
+	s.mu.Unlock()
+	sort.Slice(infos, func(i, j int) bool {
+		return infos[i].Next.Before(infos[j].Next)
+	})
+	return infos
+}
+
+// Stop 停止定时器，尚未到期的任务都不再执行，已经提交到协程池的任务不受影响
+func (s *Scheduler) Stop() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return
+	}
+	s.stopped = true
+	s.timers = nil
+	close(s.quit)
+}
+
+func (s *Scheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+// 调度协程：取出所有到期的任务提交到协程池，然后睡到下一个任务到期
+func (s *Scheduler) loop() {
+	timer := time.NewTimer(time.Hour)
+	defer timer.Stop()
+	for {
+		s.mu.Lock()
+		now := time.Now()
+		var due []*ScheduledTask
+		for len(s.timers) > 0 && !s.timers[0].next.After(now) {
+			t := heap.Pop(&s.timers).(*ScheduledTask)
+			if t.ctx.Err() != nil { // 已经取消，直接丢弃
+				continue
+			}
+			due = append(due, t)
+			if t.interval > 0 { // 周期任务按固定频率排下一次，错过的时间点直接跳过
+				for !t.next.After(now) {
+					t.next = t.next.Add(t.interval)
+				}
+				heap.Push(&s.timers, t)
+			}
+		}
+		wait := time.Hour
+		if len(s.timers) > 0 {
+			wait = s.timers[0].next.Sub(now)
+		}
+		s.mu.Unlock()
+
+		for _, t := range due {
+			t.fire()
+		}
+
+		if !timer.Stop() {
+			select {
+			case <-timer.C:
+			default:
+			}
+		}
+		timer.Reset(wait)
+		select {
+		case <-s.quit:
+			return
+		case <-s.wake:
+		case <-timer.C:
+		}
+	}
+}
+
+// 到期，提交到协程池执行
+// 调度协程不能被某一个任务卡住：一次性任务交给单独的协程阻塞提交，直到提交成功或者 ctx 结束；
+// 周期任务协程池满了就跳过这一次，不阻塞也不在调度协程中直接执行
+func (t *ScheduledTask) fire() {
+	if t.interval == 0 {
+		go func() {
+			if _, err := t.s.pool.SubmitFuture(t.ctx, t.task, t.opts...); err != nil {
+				atomic.AddInt64(&t.skips, 1)
+				return
+			}
+			atomic.AddInt64(&t.runs, 1)
+		}()
+		return
+	}
+
+	if !atomic.CompareAndSwapInt32(&t.running, 0, 1) {
+		atomic.AddInt64(&t.skips, 1)
+		return
+	}
+	f, err := t.s.pool.SubmitFuture(t.ctx, t.task, append(t.opts[:len(t.opts):len(t.opts)], withNonBlock())...)
+	if err != nil {
+		atomic.AddInt64(&t.skips, 1)
+		atomic.StoreInt32(&t.running, 0)
+		return
+	}
+	atomic.AddInt64(&t.runs, 1)
+	go func() {
+		<-f.Done()
+		atomic.StoreInt32(&t.running, 0)
+	}()
+}
+
+// ID 定时任务的编号
+func (t *ScheduledTask) ID() uint64 {
+	return t.id
+}
+
+// Cancel 取消定时任务，已经提交到协程池的那一次执行不受影响
+func (t *ScheduledTask) Cancel() {
+	s := t.s
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if t.index >= 0 && t.index < len(s.timers) && s.timers[t.index] == t {
+		heap.Remove(&s.timers, t.index)
+		s.notify()
+	}
+}
+
+// Info 定时任务的当前状态
+func (t *ScheduledTask) Info() ScheduledInfo {
+	t.s.mu.Lock()
+	defer t.s.mu.Unlock()
+	return t.info()
+}
+
+func (t *ScheduledTask) info() ScheduledInfo {
+	return ScheduledInfo{
+		ID:       t.id,
+		Next:     t.next,
+		Interval: t.interval,
+		Runs:     atomic.LoadInt64(&t.runs),
+		Skips:    atomic.LoadInt64(&t.skips),
+	}
+}
+
+// 按下一次执行时间排序的小顶堆
+type timerHeap []*ScheduledTask
+
+func (h timerHeap) Len() int { return len(h) }
+
+func (h timerHeap) Less(i, j int) bool {
+	return h[i].next.Before(h[j].next)
+}
+
+func (h timerHeap) Swap(i, j int) {
+	h[i], h[j] = h[j], h[i]
+	h[i].index = i
+	h[j].index = j
+}
+
+func (h *timerHeap) Push(x interface{}) {
+	t := x.(*ScheduledTask)
+	t.index = len(*h)
+	*h = append(*h, t)
+}
+
+func (h *timerHeap) Pop() interface{} {
+	old := *h
+	n := len(old)
+	t := old[n-1]
+	old[n-1] = nil
+	t.index = -1
+	*h = old[:n-1]
+	return t
+}
@@ -0,0 +1,150 @@
+package ants
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestScheduler_SubmitAfter(t *testing.T) {
+	s := NewScheduler(New("mypool_schedule", 2, 0, 10))
+	defer s.Stop()
+	ctx := context.Background()
+
+	begin := time.Now()
+	done := make(chan time.Duration, 2)
+	task := func(ctx context.Context) error {
+		done <- time.Since(begin)
+		return nil
+	}
+	s.SubmitAfter(ctx, 100*time.Millisecond, task)
+	s.SubmitAt(ctx, begin.Add(50*time.Millisecond), task)
+	canceled, _ := s.SubmitAfter(ctx, 80*time.Millisecond, task)
+
+	if infos := s.Scheduled(); len(infos) != 3 || infos[0].ID != 2 {
+		t.Errorf("scheduled:%+v", infos)
+	}
+	canceled.Cancel()
+	if infos := s.Scheduled(); len(infos) != 2 {
+		t.Errorf("scheduled:%+v", infos)
+	}
+
+	for _, want := range []time.Duration{50 * time.Millisecond, 100 * time.Millisecond} {
+		if d := <-done; d < want || d > want+50*time.Millisecond {
+			t.Errorf("run at:%v, want:%v", d, want)
+		}
+	}
+	select {
+	case <-done:
+		t.Errorf("canceled task should not run")
+	case <-time.After(50 * time.Millisecond):
+	}
+}
+
+func TestScheduler_Every(t *testing.T) {
+	s := NewScheduler(New("mypool_schedule_every", 2, 0, 10))
+	defer s.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// 每20ms一次，但每次执行50ms，上一次没跑完的时间点会被跳过
+	var runs int32
+	task, err := s.Every(ctx, 20*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(210 * time.Millisecond)
+	cancel()
+
+	info := task.Info()
+	if info.Skips == 0 || info.Runs != int64(atomic.LoadInt32(&runs)) {
+		t.Errorf("info:%+v, runs:%v", info, runs)
+	}
+	if n := atomic.LoadInt32(&runs); n < 3 || n > 5 {
+		t.Errorf("runs:%v", n)
+	}
+
+	// ctx 取消之后不再执行
+	time.Sleep(100 * time.Millisecond)
+	n := atomic.LoadInt32(&runs)
+	time.Sleep(100 * time.Millisecond)
+	if atomic.LoadInt32(&runs) != n {
+		t.Errorf("canceled periodic task still running")
+	}
+	if len(s.Scheduled()) != 0 {
+		t.Errorf("scheduled:%+v", s.Scheduled())
+	}
+
+	if _, err := s.Every(context.Background(), 0, nil); err != ErrInvalidInterval {
+		t.Errorf("err:%v", err)
+	}
+	s.Stop()
+	if _, err := s.SubmitAfter(context.Background(), time.Second, nil); err != ErrSchedulerStopped {
+		t.Errorf("err:%v", err)
+	}
+}
+
+func TestScheduler_PoolFull(t *testing.T) {
+	pool := New("mypool_schedule_full", 1, 0, 10)
+	s := NewScheduler(pool)
+	defer s.Stop()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// 占住唯一的worker，协程池默认阻塞提交
+	block := make(chan struct{})
+	defer close(block)
+	pool.Submit(ctx, func() error {
+		<-block
+		return nil
+	})
+
+	// 提交被拒绝时计为跳过，调度协程不会被卡住，后面的时间点照常触发
+	var runs int32
+	task, err := s.Every(ctx, 10*time.Millisecond, func(ctx context.Context) error {
+		atomic.AddInt32(&runs, 1)
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(100 * time.Millisecond)
+	info := task.Info()
+	if info.Skips < 3 || info.Runs != 0 || atomic.LoadInt32(&runs) != 0 {
+		t.Errorf("info:%+v, runs:%v", info, runs)
+	}
+}
+
+func TestScheduler_OneShotPoolFull(t *testing.T) {
+	pool := New("mypool_schedule_oneshot", 1, 0, 10)
+	s := NewScheduler(pool)
+	defer s.Stop()
+	ctx := context.Background()
+
+	block := make(chan struct{})
+	pool.Submit(ctx, func() error {
+		<-block
+		return nil
+	})
+
+	// 到期时协程池已满，一次性任务不会丢，等worker空闲之后照常执行
+	done := make(chan struct{})
+	task, _ := s.SubmitAfter(ctx, 10*time.Millisecond, func(ctx context.Context) error {
+		close(done)
+		return nil
+	})
+	time.Sleep(50 * time.Millisecond)
+	close(block)
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("one-shot task lost")
+	}
+	if info := task.Info(); info.Runs != 1 || info.Skips != 0 {
+		t.Errorf("info:%+v", info)
+	}
+}
@@ -0,0 +1,81 @@
+package ants
+
+import (
+	"context"
+	"errors"
+)
+
+// ErrPoolClosed 协程池已经（或正在）关闭，不再接收新的任务
+var ErrPoolClosed = errors.New("ants: pool is shutting down")
+
+// ShutdownReport 关闭协程池的结果
+type ShutdownReport struct {
+	Finished  int // 关闭期间正常结束（包括重试结束）的任务数
+	Abandoned int // 未能结束而被放弃的任务数，这些任务的 ctx 已经被取消
+}
+
+// 标记关闭，返回当前的在途任务数，以及全部结束时会被关闭的channel
+func (p *goPool) beginShutdown() (int, chan struct{}, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.closing {
+		return 0, nil, ErrPoolClosed
+	}
+	p.closing = true
+	close(p.quit)
+	p.cond.Broadcast() // 唤醒调度协程，队列清空后退出
+	p.notifyNotFull()  // 唤醒因为队列满而阻塞的提交者，它们会拿到 ErrPoolClosed
+	drained := make(chan struct{})
+	if len(p.inflight) == 0 {
+		close(drained)
+	} else {
+		p.drained = drained
+	}
+	return len(p.inflight), drained, nil
+}
+
+// 关闭结束，释放ants协程池，并注销监控指标
+func (p *goPool) finishShutdown() {
+	p.pool.Release()
+	p.metrics.unregister()
+}
+
+// 取消所有在途任务的 ctx，返回被取消的任务数
+func (p *goPool) cancelInflight() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	for f := range p.inflight {
+		f.Cancel()
+	}
+	return len(p.inflight)
+}
+
+// Shutdown 优雅关闭：不再接收新任务，等待排队中、执行中以及等待重试的任务结束
+// 如果 ctx 先到期，则取消剩余任务的 ctx，并将它们计入 Abandoned，同时返回 ctx 的错误
+func (p *goPool) Shutdown(ctx context.Context) (ShutdownReport, error) {
+	total, drained, err := p.beginShutdown()
+	if err != nil {
+		return ShutdownReport{}, err
+	}
+	defer p.finishShutdown()
+
+	select {
+	case <-drained:
+		return ShutdownReport{Finished: total}, nil
+	case <-ctx.Done():
+		abandoned := p.cancelInflight()
+		if abandoned == 0 { // 恰好在超时的同时全部结束
+			return ShutdownReport{Finished: total}, nil
+		}
+		return ShutdownReport{Finished: total - abandoned, Abandoned: abandoned}, ctx.Err()
+	}
+}
+
+// ShutdownNow 立即关闭：不再接收新任务，并取消所有在途任务的 ctx，不做等待
+func (p *goPool) ShutdownNow() ShutdownReport {
+	if _, _, err := p.beginShutdown(); err != nil {
+		return ShutdownReport{}
+	}
+	defer p.finishShutdown()
+	return ShutdownReport{Abandoned: p.cancelInflight()}
+}
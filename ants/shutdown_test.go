@@ -0,0 +1,80 @@
+package ants
+
+import (
+	"context"
+	"errors"
+	"log"
+	"testing"
+	"time"
+)
+
+func Test_goPool_Shutdown(t *testing.T) {
+	pool := New("mypool_shutdown", 2, 0, 10)
+	ctx := context.Background()
+	for i := 0; i < 4; i++ {
+		pool.Submit(ctx, func() error {
+			time.Sleep(100 * time.Millisecond)
+			return nil
+		})
+	}
+
+	report, err := pool.Shutdown(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Printf("report:%+v", report)
+	// 2个worker，提交第3个任务时会阻塞到有worker空闲，所以Shutdown时至少有2个在途任务
+	if report.Finished < 2 || report.Abandoned != 0 {
+		t.Errorf("report:%+v", report)
+	}
+
+	if err := pool.Submit(ctx, func() error { return nil }); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("submit after shutdown, err:%v", err)
+	}
+	if _, err := pool.Shutdown(ctx); !errors.Is(err, ErrPoolClosed) {
+		t.Errorf("shutdown twice, err:%v", err)
+	}
+}
+
+func Test_goPool_ShutdownTimeout(t *testing.T) {
+	pool := New("mypool_shutdown_timeout", 2, 0, 10)
+	ctx := context.Background()
+	pool.Submit(ctx, func() error {
+		time.Sleep(50 * time.Millisecond)
+		return nil
+	})
+	slow, _ := pool.SubmitFuture(ctx, func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+
+	timeout, cancel := context.WithTimeout(ctx, 200*time.Millisecond)
+	defer cancel()
+	report, err := pool.Shutdown(timeout)
+	if err != context.DeadlineExceeded {
+		t.Errorf("err:%v", err)
+	}
+	if report.Finished != 1 || report.Abandoned != 1 {
+		t.Errorf("report:%+v", report)
+	}
+	if err := slow.Wait(); err != context.Canceled {
+		t.Errorf("slow err:%v", err)
+	}
+}
+
+func Test_goPool_ShutdownNow(t *testing.T) {
+	pool := New("mypool_shutdown_now", 2, 0, 10)
+	f, _ := pool.SubmitFuture(context.Background(), func(ctx context.Context) error {
+		<-ctx.Done()
+		return ctx.Err()
+	})
+	time.Sleep(10 * time.Millisecond)
+
+	report := pool.ShutdownNow()
+	if report.Abandoned != 1 {
+		t.Errorf("report:%+v", report)
+	}
+	if err := f.Wait(); err != context.Canceled {
+		t.Errorf("err:%v", err)
+	}
+}
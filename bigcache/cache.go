@@ -5,42 +5,306 @@
 package bigcache
 
 import (
+	"errors"
 	"github.com/allegro/bigcache/v3"
+	"io"
+	"log"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
+// ErrNotFound key不存在（或者已经过期），调用方不必再引入allegro/bigcache的错误定义
+var ErrNotFound = errors.New("bigcache: entry not found")
+
 type Cache interface {
 	Set(k string, v []byte) error
+	// SetWithTTL 设置单条目的过期时间，过期之后 Get 视为未命中，并在CleanWindow清理时被回收
+	// 注意：条目仍然受全局LifeWindow的约束，ttl比LifeWindow长时，以LifeWindow为准
+	SetWithTTL(k string, v []byte, ttl time.Duration) error
+	// Get key不存在时返回 ErrNotFound
 	Get(k string) ([]byte, error)
+	// Delete key不存在时返回 ErrNotFound
+	Delete(k string) error
+	// Len 当前的条目数
+	Len() int
+	// Capacity 当前已经分配的内存字节数
+	Capacity() int
+	// Stats 命中、未命中等统计数据
+	Stats() Stats
+	// Iterator 遍历所有未过期的条目，遍历过程中不会阻塞读写，但不保证看到的是同一时刻的快照
+	Iterator() Iterator
+	// Reset 清空所有条目
+	Reset() error
+	// Snapshot 把所有未过期的条目（连同剩余的TTL）写入w，格式带版本号和校验
+	Snapshot(w io.Writer) error
+	// Restore 从 Snapshot 的输出中恢复，跳过损坏和已经过期的记录，返回成功恢复的条数
+	Restore(r io.Reader) (int, error)
+	// Close 关闭缓存，停止后台的清理协程，配置了 WithSnapshot 时会先做最后一次快照
+	Close() error
+}
+
+// Stats 缓存的统计数据
+type Stats struct {
+	Hits       int64 `json:"hits"`          // Get命中次数
+	Misses     int64 `json:"misses"`        // Get未命中次数
+	DelHits    int64 `json:"delete_hits"`   // Delete命中次数
+	DelMisses  int64 `json:"delete_misses"` // Delete未命中次数
+	Collisions int64 `json:"collisions"`    // key的哈希冲突次数
+}
+
+// Entry 遍历时得到的条目
+type Entry struct {
+	Key   string
+	Value []byte
+}
+
+// Iterator 条目迭代器，用法：
+//
+//	it := c.Iterator()
+//	for it.Next() {
+//		entry, err := it.Value()
+//	}
+type Iterator interface {
+	Next() bool
+	Value() (Entry, error)
 }
 
 type cache struct {
-	cache *bigcache.BigCache
+	cache      *bigcache.BigCache
+	falseHits  int64      // Get到已经过期或者负缓存的条目的次数，bigcache会把它们计为命中，统计时需要修正
+	sweep      sweepStats // 清理过期条目时的读、删，bigcache会把它们计为用户的访问，统计时需要扣除
+	quit       chan struct{}
+	once       sync.Once
+	opts       *Options
+	metrics    *cacheMetrics
+	snapMu     sync.Mutex // 后台快照和Close时的快照不能同时写同一个文件
+	lifeWindow time.Duration
 }
 
 // 这些参数组合起来，会影响到整体内存占用，实际使用中，可以多尝试配置
-func NewCache(shard, maxEntriesInWindow, MaxEntrySize int, lifeWindow, cleanWindow time.Duration) (Cache, error) {
-	c, err := bigcache.NewBigCache(bigcache.Config{
+// 等价于 NewCacheWithConfig，参数较多时建议直接使用 Config
+func NewCache(shard, maxEntriesInWindow, MaxEntrySize int, lifeWindow, cleanWindow time.Duration, options ...Option) (Cache, error) {
+	return NewCacheWithConfig(Config{
 		Shards:             shard,
-		LifeWindow:         lifeWindow,         // 超过这个时间，条目可以被删除（仅仅是可以，还需要搭配CleanWindow）
-		CleanWindow:        cleanWindow,        // 配合LifeWindow使用，才能起到过期的效果
-		MaxEntriesInWindow: maxEntriesInWindow, // lifewindow内最大条目数
-		MaxEntrySize:       MaxEntrySize,       // 单位：byte，条目最大长度
-
-		// HardMaxCacheSize: 8192, //单位：MB，硬编码写死最大内存占用
-		// CleanWindow:        5 * time.Minute, // 清理过期内存的间隔
-		// Verbose:            true,
+		LifeWindow:         lifeWindow,
+		CleanWindow:        cleanWindow,
+		MaxEntriesInWindow: maxEntriesInWindow,
+		MaxEntrySize:       MaxEntrySize,
+	}, options...)
+}
+
+// NewCacheWithConfig 按配置创建缓存，配置不合法时返回 ErrInvalidConfig
+func NewCacheWithConfig(cfg Config, options ...Option) (Cache, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	opts := reloadOptions(options...)
+	cc := &cache{quit: make(chan struct{}), opts: opts, lifeWindow: cfg.LifeWindow}
+	c, err := bigcache.NewBigCache(bigcache.Config{
+		Shards:             cfg.Shards,
+		LifeWindow:         cfg.LifeWindow,
+		CleanWindow:        cfg.CleanWindow,
+		MaxEntriesInWindow: cfg.MaxEntriesInWindow,
+		MaxEntrySize:       cfg.MaxEntrySize + entryHeaderSize, // 每个值前面都有条目头
+		HardMaxCacheSize:   cfg.HardMaxCacheSize,
+		OnRemoveWithReason: cc.onRemove,
 	})
 	if err != nil {
 		return nil, err
 	}
-	return &cache{cache: c}, nil
+	cc.cache = c
+	cc.metrics = newCacheMetrics(opts.Name, opts.MetricsRegistry)
+	if cfg.CleanWindow > 0 {
+		go cc.cleanUp(cfg.CleanWindow)
+	}
+	if path := cc.opts.SnapshotPath; path != "" {
+		if n, err := cc.restoreFromFile(path); err != nil {
+			log.Printf("bigcache: restore from %s failed, %d entries loaded: %v", path, n, err)
+		}
+		if cc.opts.SnapshotInterval > 0 {
+			go cc.snapshotLoop(path, cc.opts.SnapshotInterval)
+		}
+	}
+	return cc, nil
 }
 
 func (c *cache) Set(k string, v []byte) error {
-	return c.cache.Set(k, v)
+	return c.cache.Set(k, wrapEntry(entryValue, v, 0))
+}
+
+func (c *cache) SetWithTTL(k string, v []byte, ttl time.Duration) error {
+	return c.cache.Set(k, wrapEntry(entryValue, v, deadlineOf(ttl)))
 }
 
 func (c *cache) Get(k string) ([]byte, error) {
-	return c.cache.Get(k)
+	kind, v, err := c.lookup(k)
+	if err != nil {
+		return nil, err
+	}
+	if kind == entryNotFound {
+		return nil, ErrNotFound
+	}
+	return v, nil
+}
+
+// 读取条目并去掉条目头，已经过期的条目返回 ErrNotFound
+// 命中过期或者负缓存的条目都计入 falseHits，Get 和 LoadingCache 的统计口径一致
+func (c *cache) lookup(k string) (byte, []byte, error) {
+	b, err := c.cache.Get(k)
+	if err != nil {
+		return 0, nil, convertErr(err)
+	}
+	kind, v, deadline, err := unwrapEntry(b)
+	if err != nil {
+		return 0, nil, err
+	}
+	if expired(deadline, time.Now().UnixNano()) {
+		atomic.AddInt64(&c.falseHits, 1)
+		return 0, nil, ErrNotFound
+	}
+	if kind == entryNotFound {
+		atomic.AddInt64(&c.falseHits, 1)
+	}
+	return kind, v, nil
+}
+
+// 写入负缓存条目，表示回源确认数据不存在
+func (c *cache) setNotFound(k string, ttl time.Duration) error {
+	return c.cache.Set(k, wrapEntry(entryNotFound, nil, deadlineOf(ttl)))
+}
+
+func (c *cache) Delete(k string) error {
+	return convertErr(c.cache.Delete(k))
+}
+
+func (c *cache) Len() int {
+	return c.cache.Len()
+}
+
+func (c *cache) Capacity() int {
+	return c.cache.Capacity()
+}
+
+func (c *cache) Stats() Stats {
+	s := c.cache.Stats()
+	falseHits := atomic.LoadInt64(&c.falseHits)
+	return Stats{
+		Hits:       s.Hits - falseHits - atomic.LoadInt64(&c.sweep.hits),
+		Misses:     s.Misses + falseHits - atomic.LoadInt64(&c.sweep.misses),
+		DelHits:    s.DelHits - atomic.LoadInt64(&c.sweep.delHits),
+		DelMisses:  s.DelMisses - atomic.LoadInt64(&c.sweep.delMisses),
+		Collisions: s.Collisions,
+	}
+}
+
+func (c *cache) Iterator() Iterator {
+	return &iterator{it: c.cache.Iterator()}
+}
+
+func (c *cache) Reset() error {
+	return c.cache.Reset()
+}
+
+func (c *cache) Close() error {
+	var err error
+	c.once.Do(func() {
+		close(c.quit)
+		if path := c.opts.SnapshotPath; path != "" {
+			err = c.snapshotToFile(path)
+		}
+		if cerr := c.cache.Close(); err == nil {
+			err = cerr
+		}
+		c.metrics.unregister()
+	})
+	return err
+}
+
+// 每个CleanWindow扫描一遍，回收设置了单独TTL并且已经过期的条目
+// 全局LifeWindow的过期仍然由bigcache自己清理
+func (c *cache) cleanUp(cleanWindow time.Duration) {
+	ticker := time.NewTicker(cleanWindow)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.quit:
+			return
+		case <-ticker.C:
+			c.removeExpired()
+		}
+	}
+}
+
+func (c *cache) removeExpired() {
+	now := time.Now().UnixNano()
+	var keys []string
+	it := c.cache.Iterator()
+	for it.SetNext() {
+		info, err := it.Value()
+		if err != nil {
+			continue
+		}
+		if _, _, deadline, err := unwrapEntry(info.Value()); err == nil && expired(deadline, now) {
+			keys = append(keys, info.Key())
+		}
+	}
+	for _, k := range keys {
+		// 删除之前再确认一次，避免误删在扫描之后被重新Set的条目
+		b, err := c.cache.Get(k)
+		if err != nil {
+			atomic.AddInt64(&c.sweep.misses, 1)
+			continue
+		}
+		atomic.AddInt64(&c.sweep.hits, 1)
+		if _, _, deadline, err := unwrapEntry(b); err == nil && expired(deadline, now) {
+			if err := c.cache.Delete(k); err != nil {
+				atomic.AddInt64(&c.sweep.delMisses, 1)
+			} else {
+				atomic.AddInt64(&c.sweep.delHits, 1)
+			}
+		}
+	}
+}
+
+// 清理协程产生的访问次数
+type sweepStats struct {
+	hits, misses, delHits, delMisses int64
+}
+
+// 将allegro/bigcache的错误转换为本包的错误
+func convertErr(err error) error {
+	if errors.Is(err, bigcache.ErrEntryNotFound) {
+		return ErrNotFound
+	}
+	return err
+}
+
+// 对bigcache的迭代器做一层包装，去掉条目头，并跳过已经过期的条目和负缓存条目
+type iterator struct {
+	it    *bigcache.EntryInfoIterator
+	entry Entry
+	err   error
+}
+
+func (i *iterator) Next() bool {
+	now := time.Now().UnixNano()
+	for i.it.SetNext() {
+		info, err := i.it.Value()
+		if err != nil {
+			i.entry, i.err = Entry{}, err
+			return true
+		}
+		kind, v, deadline, err := unwrapEntry(info.Value())
+		if err == nil && (kind == entryNotFound || expired(deadline, now)) {
+			continue
+		}
+		i.entry, i.err = Entry{Key: info.Key(), Value: v}, err
+		return true
+	}
+	return false
+}
+
+func (i *iterator) Value() (Entry, error) {
+	return i.entry, i.err
 }
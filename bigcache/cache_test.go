@@ -39,3 +39,111 @@ func TestCache(t *testing.T) {
 	}
 	log.Printf("Val:%v", string(v2))
 }
+
+func TestCacheAPI(t *testing.T) {
+	c, err := NewCache(2, 1024, 256, time.Minute, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := c.Get("none"); err != ErrNotFound {
+		t.Errorf("err:%v", err)
+	}
+	if err := c.Delete("none"); err != ErrNotFound {
+		t.Errorf("err:%v", err)
+	}
+
+	_ = c.Set("foo", []byte("bar"))
+	_ = c.Set("hello", []byte("world"))
+	_, _ = c.Get("foo")
+	if c.Len() != 2 || c.Capacity() == 0 {
+		t.Errorf("len:%v, capacity:%v", c.Len(), c.Capacity())
+	}
+
+	entries := map[string]string{}
+	it := c.Iterator()
+	for it.Next() {
+		entry, err := it.Value()
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries[entry.Key] = string(entry.Value)
+	}
+	if len(entries) != 2 || entries["foo"] != "bar" || entries["hello"] != "world" {
+		t.Errorf("entries:%v", entries)
+	}
+
+	if err := c.Delete("foo"); err != nil {
+		t.Error(err)
+	}
+	stats := c.Stats()
+	log.Printf("Stats:%+v", stats)
+	if stats.Hits != 1 || stats.Misses != 1 || stats.DelHits != 1 || stats.DelMisses != 1 {
+		t.Errorf("stats:%+v", stats)
+	}
+
+	if err := c.Reset(); err != nil {
+		t.Error(err)
+	}
+	if c.Len() != 0 {
+		t.Errorf("len:%v", c.Len())
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	c, err := NewCache(2, 1024, 256, time.Minute, 100*time.Millisecond)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	_ = c.SetWithTTL("token", []byte("abc"), 50*time.Millisecond)
+	_ = c.Set("config", []byte("blob"))
+	if v, err := c.Get("token"); err != nil || string(v) != "abc" {
+		t.Errorf("v:%s, err:%v", v, err)
+	}
+
+	time.Sleep(60 * time.Millisecond)
+	// 单条目过期，视为未命中，其他条目不受影响
+	if _, err := c.Get("token"); err != ErrNotFound {
+		t.Errorf("err:%v", err)
+	}
+	if v, err := c.Get("config"); err != nil || string(v) != "blob" {
+		t.Errorf("v:%s, err:%v", v, err)
+	}
+	if it := c.Iterator(); !it.Next() {
+		t.Errorf("iterator should have config")
+	} else if entry, _ := it.Value(); entry.Key != "config" || it.Next() {
+		t.Errorf("iterator should skip expired entry, got:%v", entry.Key)
+	}
+	if stats := c.Stats(); stats.Hits != 2 || stats.Misses != 1 {
+		t.Errorf("stats:%+v", stats)
+	}
+
+	// CleanWindow清理之后被回收
+	time.Sleep(100 * time.Millisecond)
+	if c.Len() != 1 {
+		t.Errorf("len:%v", c.Len())
+	}
+}
+
+func TestCacheSweepStats(t *testing.T) {
+	c, err := NewCache(2, 1024, 256, time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	_ = c.SetWithTTL("a", []byte("1"), 10*time.Millisecond)
+	_ = c.SetWithTTL("b", []byte("2"), 10*time.Millisecond)
+	time.Sleep(20 * time.Millisecond)
+	c.(*cache).removeExpired()
+
+	// 清理协程的读、删不计入统计
+	if c.Len() != 0 {
+		t.Errorf("len:%v", c.Len())
+	}
+	if stats := c.Stats(); stats != (Stats{}) {
+		t.Errorf("stats:%+v", stats)
+	}
+}
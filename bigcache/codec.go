@@ -0,0 +1,195 @@
+package bigcache
+
+import (
+	"bytes"
+	"compress/gzip"
+	"encoding"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"time"
+)
+
+// Codec 对象和[]byte之间的编解码
+type Codec interface {
+	Name() string
+	Marshal(v interface{}) ([]byte, error)
+	Unmarshal(data []byte, v interface{}) error
+}
+
+// ErrUnsupportedType 编解码器不支持该类型
+var ErrUnsupportedType = errors.New("bigcache: unsupported type")
+
+// CodecError 编解码、压缩过程中的错误，可以用 errors.As 取出，查看是哪一步出的错
+type CodecError struct {
+	Op    string // marshal、unmarshal、compress、decompress
+	Codec string
+	Key   string
+	Err   error
+}
+
+func (e *CodecError) Error() string {
+	return fmt.Sprintf("bigcache: %s %s key[%s]: %v", e.Codec, e.Op, e.Key, e.Err)
+}
+
+func (e *CodecError) Unwrap() error {
+	return e.Err
+}
+
+var (
+	JSONCodec   Codec = jsonCodec{}
+	GobCodec    Codec = gobCodec{}
+	BinaryCodec Codec = binaryCodec{}
+)
+
+type jsonCodec struct{}
+
+func (jsonCodec) Name() string { return "json" }
+
+func (jsonCodec) Marshal(v interface{}) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonCodec) Unmarshal(data []byte, v interface{}) error {
+	return json.Unmarshal(data, v)
+}
+
+type gobCodec struct{}
+
+func (gobCodec) Name() string { return "gob" }
+
+func (gobCodec) Marshal(v interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := gob.NewEncoder(&buf).Encode(v); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func (gobCodec) Unmarshal(data []byte, v interface{}) error {
+	return gob.NewDecoder(bytes.NewReader(data)).Decode(v)
+}
+
+// 紧凑的二进制编码，由类型自己实现序列化，适用于protobuf生成的结构体（Marshal/Unmarshal方法），
+// 或者实现了 encoding.BinaryMarshaler/BinaryUnmarshaler 的类型
+type binaryCodec struct{}
+
+type protoMarshaler interface {
+	Marshal() ([]byte, error)
+}
+
+type protoUnmarshaler interface {
+	Unmarshal(data []byte) error
+}
+
+func (binaryCodec) Name() string { return "binary" }
+
+func (binaryCodec) Marshal(v interface{}) ([]byte, error) {
+	switch m := v.(type) {
+	case protoMarshaler:
+		return m.Marshal()
+	case encoding.BinaryMarshaler:
+		return m.MarshalBinary()
+	}
+	return nil, ErrUnsupportedType
+}
+
+func (binaryCodec) Unmarshal(data []byte, v interface{}) error {
+	switch m := v.(type) {
+	case protoUnmarshaler:
+		return m.Unmarshal(data)
+	case encoding.BinaryUnmarshaler:
+		return m.UnmarshalBinary(data)
+	}
+	return ErrUnsupportedType
+}
+
+// 编码之后的数据，前面加1个字节标记是否压缩
+const (
+	rawValue  byte = 0
+	gzipValue byte = 1
+)
+
+// TypedCache 在Cache之上加一层编解码，直接存取结构体，调用方不用再在Set、Get外面自己做序列化
+// compressThreshold 大于0时，编码之后超过该长度的数据会用gzip压缩，让大对象也能放进MaxEntrySize
+type TypedCache struct {
+	cache             Cache
+	codec             Codec
+	compressThreshold int
+}
+
+func NewTypedCache(c Cache, codec Codec, compressThreshold int) *TypedCache {
+	return &TypedCache{
+		cache:             c,
+		codec:             codec,
+		compressThreshold: compressThreshold,
+	}
+}
+
+func (t *TypedCache) Set(k string, v interface{}) error {
+	return t.SetWithTTL(k, v, 0)
+}
+
+func (t *TypedCache) SetWithTTL(k string, v interface{}, ttl time.Duration) error {
+	b, err := t.encode(k, v)
+	if err != nil {
+		return err
+	}
+	return t.cache.SetWithTTL(k, b, ttl)
+}
+
+// Get 读取并解码到 out 中（out必须是指针），key不存在时返回 ErrNotFound
+func (t *TypedCache) Get(k string, out interface{}) error {
+	b, err := t.cache.Get(k)
+	if err != nil {
+		return err
+	}
+	return t.decode(k, b, out)
+}
+
+func (t *TypedCache) encode(k string, v interface{}) ([]byte, error) {
+	data, err := t.codec.Marshal(v)
+	if err != nil {
+		return nil, &CodecError{Op: "marshal", Codec: t.codec.Name(), Key: k, Err: err}
+	}
+	if t.compressThreshold <= 0 || len(data) <= t.compressThreshold {
+		return append([]byte{rawValue}, data...), nil
+	}
+
+	var buf bytes.Buffer
+	buf.WriteByte(gzipValue)
+	w := gzip.NewWriter(&buf)
+	if _, err := w.Write(data); err != nil {
+		return nil, &CodecError{Op: "compress", Codec: t.codec.Name(), Key: k, Err: err}
+	}
+	if err := w.Close(); err != nil {
+		return nil, &CodecError{Op: "compress", Codec: t.codec.Name(), Key: k, Err: err}
+	}
+	return buf.Bytes(), nil
+}
+
+func (t *TypedCache) decode(k string, b []byte, out interface{}) error {
+	if len(b) == 0 {
+		return &CodecError{Op: "unmarshal", Codec: t.codec.Name(), Key: k, Err: ErrCorruptEntry}
+	}
+	data := b[1:]
+	switch b[0] {
+	case rawValue:
+	case gzipValue:
+		r, err := gzip.NewReader(bytes.NewReader(data))
+		if err != nil {
+			return &CodecError{Op: "decompress", Codec: t.codec.Name(), Key: k, Err: err}
+		}
+		if data, err = ioutil.ReadAll(r); err != nil {
+			return &CodecError{Op: "decompress", Codec: t.codec.Name(), Key: k, Err: err}
+		}
+	default:
+		return &CodecError{Op: "unmarshal", Codec: t.codec.Name(), Key: k, Err: ErrCorruptEntry}
+	}
+	if err := t.codec.Unmarshal(data, out); err != nil {
+		return &CodecError{Op: "unmarshal", Codec: t.codec.Name(), Key: k, Err: err}
+	}
+	return nil
+}
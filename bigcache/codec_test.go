@@ -0,0 +1,101 @@
+package bigcache
+
+import (
+	"encoding/binary"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+type user struct {
+	ID   int
+	Name string
+	Tags []string
+}
+
+// 模拟protobuf生成的结构体
+type point struct {
+	X, Y uint32
+}
+
+func (p *point) Marshal() ([]byte, error) {
+	b := make([]byte, 8)
+	binary.BigEndian.PutUint32(b, p.X)
+	binary.BigEndian.PutUint32(b[4:], p.Y)
+	return b, nil
+}
+
+func (p *point) Unmarshal(b []byte) error {
+	if len(b) != 8 {
+		return errors.New("bad point")
+	}
+	p.X, p.Y = binary.BigEndian.Uint32(b), binary.BigEndian.Uint32(b[4:])
+	return nil
+}
+
+func TestTypedCache(t *testing.T) {
+	c, err := NewCache(2, 1024, 256, time.Minute, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, codec := range []Codec{JSONCodec, GobCodec} {
+		tc := NewTypedCache(c, codec, 0)
+		in := user{ID: 1, Name: "foo", Tags: []string{"a", "b"}}
+		if err := tc.Set("user", in); err != nil {
+			t.Fatal(err)
+		}
+		var out user
+		if err := tc.Get("user", &out); err != nil {
+			t.Fatal(err)
+		}
+		if out.ID != 1 || out.Name != "foo" || len(out.Tags) != 2 {
+			t.Errorf("%v out:%+v", codec.Name(), out)
+		}
+	}
+
+	tc := NewTypedCache(c, BinaryCodec, 0)
+	if err := tc.Set("point", &point{X: 3, Y: 4}); err != nil {
+		t.Fatal(err)
+	}
+	var p point
+	if err := tc.Get("point", &p); err != nil || p.X != 3 || p.Y != 4 {
+		t.Errorf("point:%+v, err:%v", p, err)
+	}
+
+	// 类型错误
+	var ce *CodecError
+	if err := tc.Set("user", user{}); !errors.As(err, &ce) || ce.Op != "marshal" || !errors.Is(err, ErrUnsupportedType) {
+		t.Errorf("err:%v", err)
+	}
+	_ = c.Set("bad", []byte{rawValue, 1, 2})
+	if err := tc.Get("bad", &p); !errors.As(err, &ce) || ce.Op != "unmarshal" {
+		t.Errorf("err:%v", err)
+	}
+	if err := tc.Get("none", &p); err != ErrNotFound {
+		t.Errorf("err:%v", err)
+	}
+}
+
+func TestTypedCacheCompress(t *testing.T) {
+	// 编码之后超过阈值的大对象，压缩之后落在MaxEntrySize（256字节）之内
+	c, err := NewCache(2, 1024, 256, time.Minute, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tc := NewTypedCache(c, JSONCodec, 128)
+
+	in := user{Name: strings.Repeat("x", 4096)}
+	if err := tc.Set("big", in); err != nil {
+		t.Fatal(err)
+	}
+	raw, _ := c.Get("big")
+	if raw[0] != gzipValue || len(raw) > 256 {
+		t.Errorf("raw len:%v", len(raw))
+	}
+	var out user
+	if err := tc.Get("big", &out); err != nil || out.Name != in.Name {
+		t.Errorf("err:%v", err)
+	}
+}
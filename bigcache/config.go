@@ -0,0 +1,193 @@
+package bigcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrInvalidConfig 配置不合法，具体原因见错误信息
+var ErrInvalidConfig = errors.New("bigcache: invalid config")
+
+// Config 缓存的配置，可以直接从json、yaml配置文件中加载，时间间隔写成 "10m"、"1h30m" 这样的字符串，也兼容纳秒数
+// 这些参数组合起来，会影响到整体内存占用，可以先用 EstimateMemory 估算一下
+type Config struct {
+	Shards             int           `json:"shards" yaml:"shards"`                               // 分片数，必须是2的幂
+	LifeWindow         time.Duration `json:"life_window" yaml:"life_window"`                     // 超过这个时间，条目可以被删除（仅仅是可以，还需要搭配CleanWindow）
+	CleanWindow        time.Duration `json:"clean_window" yaml:"clean_window"`                   // 清理过期条目的间隔，0表示不清理
+	MaxEntriesInWindow int           `json:"max_entries_in_window" yaml:"max_entries_in_window"` // lifewindow内最大条目数，只用于计算初始分配的内存
+	MaxEntrySize       int           `json:"max_entry_size" yaml:"max_entry_size"`               // 单位：byte，value最大长度，只用于计算初始分配的内存
+	HardMaxCacheSize   int           `json:"hard_max_cache_size" yaml:"hard_max_cache_size"`     // 单位：MB，最大内存占用，0表示不限制，达到上限之后淘汰最老的条目
+}
+
+// 从配置文件加载时使用，时间间隔用 Duration 解析
+type rawConfig struct {
+	Shards             int      `json:"shards" yaml:"shards"`
+	LifeWindow         Duration `json:"life_window" yaml:"life_window"`
+	CleanWindow        Duration `json:"clean_window" yaml:"clean_window"`
+	MaxEntriesInWindow int      `json:"max_entries_in_window" yaml:"max_entries_in_window"`
+	MaxEntrySize       int      `json:"max_entry_size" yaml:"max_entry_size"`
+	HardMaxCacheSize   int      `json:"hard_max_cache_size" yaml:"hard_max_cache_size"`
+}
+
+func (c *Config) raw() rawConfig {
+	return rawConfig{
+		Shards:             c.Shards,
+		LifeWindow:         Duration(c.LifeWindow),
+		CleanWindow:        Duration(c.CleanWindow),
+		MaxEntriesInWindow: c.MaxEntriesInWindow,
+		MaxEntrySize:       c.MaxEntrySize,
+		HardMaxCacheSize:   c.HardMaxCacheSize,
+	}
+}
+
+func (c *Config) load(raw rawConfig) {
+	*c = Config{
+		Shards:             raw.Shards,
+		LifeWindow:         time.Duration(raw.LifeWindow),
+		CleanWindow:        time.Duration(raw.CleanWindow),
+		MaxEntriesInWindow: raw.MaxEntriesInWindow,
+		MaxEntrySize:       raw.MaxEntrySize,
+		HardMaxCacheSize:   raw.HardMaxCacheSize,
+	}
+}
+
+func (c *Config) UnmarshalJSON(b []byte) error {
+	raw := c.raw()
+	if err := json.Unmarshal(b, &raw); err != nil {
+		return err
+	}
+	c.load(raw)
+	return nil
+}
+
+// UnmarshalYAML 兼容 gopkg.in/yaml.v2 和 yaml.v3
+func (c *Config) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	raw := c.raw()
+	if err := unmarshal(&raw); err != nil {
+		return err
+	}
+	c.load(raw)
+	return nil
+}
+
+// Duration 配置文件中的时间间隔，支持 time.ParseDuration 的格式，比如 "10m"，也兼容纳秒数
+type Duration time.Duration
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+func (d *Duration) UnmarshalJSON(b []byte) error {
+	var v interface{}
+	if err := json.Unmarshal(b, &v); err != nil {
+		return err
+	}
+	return d.set(v)
+}
+
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+func (d *Duration) UnmarshalYAML(unmarshal func(interface{}) error) error {
+	var v interface{}
+	if err := unmarshal(&v); err != nil {
+		return err
+	}
+	return d.set(v)
+}
+
+func (d *Duration) set(v interface{}) error {
+	switch v := v.(type) {
+	case string:
+		dur, err := time.ParseDuration(v)
+		if err != nil {
+			return fmt.Errorf("%w: %v", ErrInvalidConfig, err)
+		}
+		*d = Duration(dur)
+	case float64: // json的数字
+		*d = Duration(v)
+	case int: // yaml的数字
+		*d = Duration(v)
+	case int64:
+		*d = Duration(v)
+	case uint64:
+		*d = Duration(v)
+	default:
+		return fmt.Errorf("%w: invalid duration %v", ErrInvalidConfig, v)
+	}
+	return nil
+}
+
+// Validate 检查配置是否合法
+func (c Config) Validate() error {
+	if c.Shards <= 0 || c.Shards&(c.Shards-1) != 0 {
+		return fmt.Errorf("%w: shards must be a power of two, got %d", ErrInvalidConfig, c.Shards)
+	}
+	if c.LifeWindow < 0 {
+		return fmt.Errorf("%w: life_window must not be negative, got %v", ErrInvalidConfig, c.LifeWindow)
+	}
+	if c.CleanWindow < 0 {
+		return fmt.Errorf("%w: clean_window must not be negative, got %v", ErrInvalidConfig, c.CleanWindow)
+	}
+	if c.MaxEntriesInWindow <= 0 {
+		return fmt.Errorf("%w: max_entries_in_window must be positive, got %d", ErrInvalidConfig, c.MaxEntriesInWindow)
+	}
+	if c.MaxEntrySize <= 0 {
+		return fmt.Errorf("%w: max_entry_size must be positive, got %d", ErrInvalidConfig, c.MaxEntrySize)
+	}
+	if c.HardMaxCacheSize < 0 {
+		return fmt.Errorf("%w: hard_max_cache_size must not be negative, got %d", ErrInvalidConfig, c.HardMaxCacheSize)
+	}
+	return nil
+}
+
+// MemoryEstimate 内存占用的估算结果，单位：byte
+type MemoryEstimate struct {
+	Initial int64 `json:"initial"` // 创建时就会分配的内存
+	Max     int64 `json:"max"`     // 最大的内存占用，0表示没有上限（未设置HardMaxCacheSize）
+}
+
+// 和allegro/bigcache的实现保持一致
+const (
+	minimumEntriesInShard = 10 // 每个分片初始至少能放下的条目数
+	shardEntryHeaderSize  = 18 // 分片中每个条目的头：时间戳、hash、key长度
+	mapBytesPerEntry      = 18 // map[uint64]uint32 平均每个元素的开销（按装载因子6.5估算）
+)
+
+// EstimateMemory 粗略估算配置对应的内存占用，不包括key本身和Go运行时的额外开销
+// 每个分片初始分配 MaxEntriesInWindow/Shards 个条目的空间（每个条目是 MaxEntrySize 加上条目头），以及对应的索引；
+// 之后按需扩容，直到 HardMaxCacheSize
+func EstimateMemory(cfg Config) (MemoryEstimate, error) {
+	if err := cfg.Validate(); err != nil {
+		return MemoryEstimate{}, err
+	}
+	shards := int64(cfg.Shards)
+	entrySize := int64(cfg.MaxEntrySize) + entryHeaderSize // 每个值前面都有条目头（类型、过期时间）
+	entriesPerShard := int64(cfg.MaxEntriesInWindow) / shards
+	if entriesPerShard < minimumEntriesInShard {
+		entriesPerShard = minimumEntriesInShard
+	}
+	maxShardBytes := int64(cfg.HardMaxCacheSize) * 1024 * 1024 / shards
+
+	queue := entriesPerShard * entrySize
+	if maxShardBytes > 0 && queue > maxShardBytes {
+		queue = maxShardBytes
+	}
+	// 每个分片有两个索引map（hashmap和hashmapStats），以及一个条目大小的缓冲区
+	index := 2 * entriesPerShard * mapBytesPerEntry
+	buffer := entrySize + shardEntryHeaderSize
+	est := MemoryEstimate{Initial: shards * (queue + index + buffer)}
+
+	if maxShardBytes > 0 {
+		// 达到上限时，按每个条目都是MaxEntrySize估算条目数，索引不会缩容
+		maxEntries := maxShardBytes / (entrySize + shardEntryHeaderSize)
+		if maxEntries < entriesPerShard {
+			maxEntries = entriesPerShard
+		}
+		est.Max = shards * (maxShardBytes + 2*maxEntries*mapBytesPerEntry + buffer)
+	}
+	return est, nil
+}
@@ -0,0 +1,125 @@
+package bigcache
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+func TestConfig(t *testing.T) {
+	var cfg Config
+	data := `{"shards":4,"life_window":60000000000,"max_entries_in_window":1024,"max_entry_size":256,"hard_max_cache_size":1}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Shards != 4 || cfg.LifeWindow != time.Minute || cfg.HardMaxCacheSize != 1 {
+		t.Errorf("cfg:%+v", cfg)
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Error(err)
+	}
+
+	// 时间间隔可以写成字符串
+	cfg = Config{}
+	data = `{"shards":4,"life_window":"10m","clean_window":"1m30s","max_entries_in_window":1024,"max_entry_size":256}`
+	if err := json.Unmarshal([]byte(data), &cfg); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.LifeWindow != 10*time.Minute || cfg.CleanWindow != 90*time.Second || cfg.HardMaxCacheSize != 0 {
+		t.Errorf("cfg:%+v", cfg)
+	}
+	if err := json.Unmarshal([]byte(`{"life_window":"10x"}`), &cfg); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("err:%v", err)
+	}
+
+	// yaml解析出来的值，字符串或者整数
+	yamlValue := func(v interface{}) func(interface{}) error {
+		return func(out interface{}) error {
+			*(out.(*interface{})) = v
+			return nil
+		}
+	}
+	var d Duration
+	if err := d.UnmarshalYAML(yamlValue("1h")); err != nil || time.Duration(d) != time.Hour {
+		t.Errorf("d:%v, err:%v", time.Duration(d), err)
+	}
+	if err := d.UnmarshalYAML(yamlValue(5000)); err != nil || d != 5000 {
+		t.Errorf("d:%v, err:%v", time.Duration(d), err)
+	}
+
+	bad := cfg
+	bad.Shards = 3
+	if err := bad.Validate(); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("err:%v", err)
+	}
+	if _, err := NewCacheWithConfig(bad); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("err:%v", err)
+	}
+	if _, err := NewCache(3, 1024, 256, time.Minute, 0); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("err:%v", err)
+	}
+}
+
+func TestEstimateMemory(t *testing.T) {
+	est, err := EstimateMemory(Config{Shards: 4, MaxEntriesInWindow: 1024, MaxEntrySize: 256})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 4个分片，每个分片256个条目
+	entrySize := 256 + entryHeaderSize
+	want := int64(4 * (256*entrySize + 2*256*mapBytesPerEntry + entrySize + shardEntryHeaderSize))
+	if est.Initial != want || est.Max != 0 {
+		t.Errorf("est:%+v, want:%v", est, want)
+	}
+
+	est, err = EstimateMemory(Config{Shards: 4, MaxEntriesInWindow: 1 << 20, MaxEntrySize: 256, HardMaxCacheSize: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// 初始分配受上限约束
+	if est.Initial < 1<<20 || est.Max < est.Initial {
+		t.Errorf("est:%+v", est)
+	}
+
+	if _, err := EstimateMemory(Config{Shards: 6}); !errors.Is(err, ErrInvalidConfig) {
+		t.Errorf("err:%v", err)
+	}
+}
+
+func TestHardMaxCacheSize(t *testing.T) {
+	var noSpace int
+	registry := gometrics.NewRegistry()
+	c, err := NewCacheWithConfig(Config{
+		Shards:             1,
+		LifeWindow:         time.Minute,
+		MaxEntriesInWindow: 16,
+		MaxEntrySize:       1024,
+		HardMaxCacheSize:   1,
+	}, WithName("hardmax"), WithMetricsRegistry(registry), WithOnRemove(func(key string, value []byte, reason RemoveReason) {
+		if reason == NoSpace {
+			noSpace++
+		}
+	}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	v := make([]byte, 1000)
+	for i := 0; i < 2000; i++ {
+		if err := c.Set(fmt.Sprint(i), v); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if noSpace == 0 || c.Capacity() > 1<<20 {
+		t.Errorf("noSpace:%v, capacity:%v", noSpace, c.Capacity())
+	}
+	counter := registry.Get("bigcache.hardmax.evictions.nospace").(gometrics.Counter)
+	if counter.Count() != int64(noSpace) {
+		t.Errorf("count:%v, noSpace:%v", counter.Count(), noSpace)
+	}
+}
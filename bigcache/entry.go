@@ -0,0 +1,49 @@
+package bigcache
+
+import (
+	"encoding/binary"
+	"errors"
+	"time"
+)
+
+// 每个条目在存入bigcache时，前面都加上一个固定长度的头，记录条目自己的过期时间：
+//
+//	| kind(1字节) | deadline(8字节，UnixNano，0表示只受全局LifeWindow约束) | value |
+//
+// 这样不需要改动bigcache分片的内存布局，就能支持单条目的TTL
+const (
+	entryValue      byte = 1 // 普通条目
+	entryNotFound   byte = 2 // 负缓存条目：回源确认数据不存在，value为空
+	entryHeaderSize      = 9
+)
+
+// ErrCorruptEntry 条目的头部不合法
+var ErrCorruptEntry = errors.New("bigcache: corrupt entry")
+
+func wrapEntry(kind byte, v []byte, deadline int64) []byte {
+	b := make([]byte, entryHeaderSize+len(v))
+	b[0] = kind
+	binary.LittleEndian.PutUint64(b[1:entryHeaderSize], uint64(deadline))
+	copy(b[entryHeaderSize:], v)
+	return b
+}
+
+func unwrapEntry(b []byte) (kind byte, v []byte, deadline int64, err error) {
+	if len(b) < entryHeaderSize || (b[0] != entryValue && b[0] != entryNotFound) {
+		return 0, nil, 0, ErrCorruptEntry
+	}
+	deadline = int64(binary.LittleEndian.Uint64(b[1:entryHeaderSize]))
+	return b[0], b[entryHeaderSize:], deadline, nil
+}
+
+// 计算过期时间点，ttl<=0 表示不设置单独的过期时间
+func deadlineOf(ttl time.Duration) int64 {
+	if ttl <= 0 {
+		return 0
+	}
+	return time.Now().Add(ttl).UnixNano()
+}
+
+func expired(deadline, now int64) bool {
+	return deadline > 0 && deadline <= now
+}
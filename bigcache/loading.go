@@ -0,0 +1,139 @@
+package bigcache
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// DefaultLoadTimeout 回源的默认超时时间
+const DefaultLoadTimeout = 10 * time.Second
+
+// Loader 缓存未命中时的回源函数，比如读DB；确认数据不存在时应当返回 ErrNotFound
+type Loader func(ctx context.Context, key string) ([]byte, error)
+
+// LoadingCache 带回源的缓存（read-through）
+// 同一个key同一时刻大量未命中时，只有一个请求会真正回源，其他请求等待并共享结果，防止缓存击穿把DB压垮
+// 用法参考 singleflight 包里的 getDataWithSF，这里把它做成了可以复用的组件
+type LoadingCache struct {
+	Cache
+	group       singleflight.Group
+	ttl         time.Duration // 回源得到的数据的TTL，0表示只受全局LifeWindow约束
+	negativeTTL time.Duration // 回源确认数据不存在时，负缓存的TTL，0表示不做负缓存
+	loadTimeout time.Duration // 单次回源的超时时间
+}
+
+type LoadingOption func(l *LoadingCache)
+
+// WithLoadTimeout 设置单次回源的超时时间，默认 DefaultLoadTimeout
+func WithLoadTimeout(timeout time.Duration) LoadingOption {
+	return func(l *LoadingCache) {
+		l.loadTimeout = timeout
+	}
+}
+
+// 支持负缓存的缓存实现，NewCache 创建的缓存都支持
+type negativeCache interface {
+	lookup(k string) (byte, []byte, error)
+	setNotFound(k string, ttl time.Duration) error
+}
+
+// NewLoadingCache 在已有的缓存之上包装回源逻辑
+// negativeTTL 通常设置得比较短，既能挡住对不存在数据的反复穿透，又不至于让新写入的数据长时间不可见
+func NewLoadingCache(c Cache, ttl, negativeTTL time.Duration, opts ...LoadingOption) *LoadingCache {
+	l := &LoadingCache{
+		Cache:       c,
+		ttl:         ttl,
+		negativeTTL: negativeTTL,
+		loadTimeout: DefaultLoadTimeout,
+	}
+	for _, opt := range opts {
+		opt(l)
+	}
+	return l
+}
+
+// GetOrLoad 先读缓存，未命中则调用 loader 回源并种缓存
+// 并发回源时，loader 拿到的 ctx 带有第一个请求 ctx 中的值，但不继承它的取消和超时，只受回源自己的超时控制
+// 任何一个请求的 ctx 到期都只会让自己不再等待，不影响回源本身，也不会让其他等待的请求跟着失败
+func (l *LoadingCache) GetOrLoad(ctx context.Context, key string, loader Loader) ([]byte, error) {
+	if v, hit, err := l.get(key); hit || err != nil {
+		return v, err
+	}
+
+	ch := l.group.DoChan(key, func() (interface{}, error) {
+		// 拿到回源权之后再查一次，可能刚刚有别的请求种好了缓存
+		if v, hit, err := l.get(key); hit || err != nil {
+			return v, err
+		}
+		loadCtx, cancel := context.WithTimeout(detach(ctx), l.loadTimeout)
+		defer cancel()
+		v, err := loader(loadCtx, key)
+		if errors.Is(err, ErrNotFound) {
+			l.setNotFound(key)
+			return nil, ErrNotFound
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := l.Cache.SetWithTTL(key, v, l.ttl); err != nil {
+			return nil, err
+		}
+		return v, nil
+	})
+
+	select {
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case r := <-ch:
+		if r.Err != nil {
+			return nil, r.Err
+		}
+		return r.Val.([]byte), nil
+	}
+}
+
+// 读缓存，hit 表示命中了（包括命中负缓存，此时返回 ErrNotFound）
+func (l *LoadingCache) get(key string) (v []byte, hit bool, err error) {
+	if nc, ok := l.Cache.(negativeCache); ok && l.negativeTTL > 0 {
+		kind, v, err := nc.lookup(key)
+		if errors.Is(err, ErrNotFound) {
+			return nil, false, nil
+		}
+		if err != nil {
+			return nil, false, err
+		}
+		if kind == entryNotFound {
+			return nil, true, ErrNotFound
+		}
+		return v, true, nil
+	}
+
+	v, err = l.Cache.Get(key)
+	if errors.Is(err, ErrNotFound) {
+		return nil, false, nil
+	}
+	return v, err == nil, err
+}
+
+func (l *LoadingCache) setNotFound(key string) {
+	if nc, ok := l.Cache.(negativeCache); ok && l.negativeTTL > 0 {
+		_ = nc.setNotFound(key, l.negativeTTL)
+	}
+}
+
+// 只保留值、不继承取消和超时的 ctx，让回源不受某一个请求方的影响
+type detachedContext struct {
+	parent context.Context
+}
+
+func detach(ctx context.Context) context.Context {
+	return detachedContext{parent: ctx}
+}
+
+func (detachedContext) Deadline() (time.Time, bool)         { return time.Time{}, false }
+func (detachedContext) Done() <-chan struct{}               { return nil }
+func (detachedContext) Err() error                          { return nil }
+func (c detachedContext) Value(key interface{}) interface{} { return c.parent.Value(key) }
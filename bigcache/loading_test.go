@@ -0,0 +1,138 @@
+package bigcache
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+func TestLoadingCache(t *testing.T) {
+	c, err := NewCache(2, 1024, 256, time.Minute, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lc := NewLoadingCache(c, time.Minute, 50*time.Millisecond)
+
+	// 并发未命中，只回源一次
+	var loads int32
+	loader := func(ctx context.Context, key string) ([]byte, error) {
+		atomic.AddInt32(&loads, 1)
+		time.Sleep(50 * time.Millisecond)
+		return []byte("data"), nil
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			v, err := lc.GetOrLoad(context.Background(), "haha", loader)
+			if err != nil || string(v) != "data" {
+				t.Errorf("v:%s, err:%v", v, err)
+			}
+		}()
+	}
+	wg.Wait()
+	if loads != 1 {
+		t.Errorf("loads:%v", loads)
+	}
+	// 已经种好缓存
+	if v, err := c.Get("haha"); err != nil || string(v) != "data" {
+		t.Errorf("v:%s, err:%v", v, err)
+	}
+}
+
+func TestLoadingCacheNegative(t *testing.T) {
+	c, err := NewCache(2, 1024, 256, time.Minute, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lc := NewLoadingCache(c, time.Minute, 50*time.Millisecond)
+
+	var loads int32
+	loader := func(ctx context.Context, key string) ([]byte, error) {
+		atomic.AddInt32(&loads, 1)
+		return nil, ErrNotFound
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := lc.GetOrLoad(context.Background(), "none", loader); err != ErrNotFound {
+			t.Errorf("err:%v", err)
+		}
+	}
+	// 负缓存期间不再回源，普通的Get也视为不存在
+	if loads != 1 {
+		t.Errorf("loads:%v", loads)
+	}
+	if _, err := c.Get("none"); err != ErrNotFound {
+		t.Errorf("err:%v", err)
+	}
+	// 命中负缓存，无论是经过 GetOrLoad 还是 Get，都计为未命中
+	if s := c.Stats(); s.Hits != 0 || s.Misses != 5 {
+		t.Errorf("stats:%+v", s)
+	}
+
+	// 负缓存过期之后重新回源
+	time.Sleep(60 * time.Millisecond)
+	lc.GetOrLoad(context.Background(), "none", loader)
+	if loads != 2 {
+		t.Errorf("loads:%v", loads)
+	}
+}
+
+type ctxKey struct{}
+
+func TestLoadingCacheCallerCancel(t *testing.T) {
+	c, err := NewCache(2, 1024, 256, time.Minute, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	lc := NewLoadingCache(c, time.Minute, 0, WithLoadTimeout(time.Second))
+
+	started := make(chan struct{})
+	loader := func(ctx context.Context, key string) ([]byte, error) {
+		close(started)
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(50 * time.Millisecond):
+		}
+		if ctx.Value(ctxKey{}) != "first" {
+			t.Errorf("value:%v", ctx.Value(ctxKey{}))
+		}
+		return []byte("data"), nil
+	}
+
+	// 第一个请求方取消，不影响回源本身，也不影响其他等待的请求
+	first, cancel := context.WithCancel(context.WithValue(context.Background(), ctxKey{}, "first"))
+	errs := make(chan error, 1)
+	go func() {
+		_, err := lc.GetOrLoad(first, "haha", loader)
+		errs <- err
+	}()
+	<-started
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		v, err := lc.GetOrLoad(context.Background(), "haha", loader)
+		if err != nil || string(v) != "data" {
+			t.Errorf("v:%s, err:%v", v, err)
+		}
+	}()
+	time.Sleep(10 * time.Millisecond)
+	cancel()
+	if err := <-errs; err != context.Canceled {
+		t.Errorf("err:%v", err)
+	}
+	<-done
+
+	// 回源自己的超时
+	lc = NewLoadingCache(c, time.Minute, 0, WithLoadTimeout(20*time.Millisecond))
+	_, err = lc.GetOrLoad(context.Background(), "slow", func(ctx context.Context, key string) ([]byte, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	if err != context.DeadlineExceeded {
+		t.Errorf("err:%v", err)
+	}
+}
@@ -0,0 +1,63 @@
+package bigcache
+
+import (
+	"strconv"
+	"sync/atomic"
+	"time"
+
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+type Option func(opts *Options)
+
+// 未命名缓存的序号
+var unnamedSeq int64
+
+type Options struct {
+	Name             string             // 缓存的名字，用作监控指标的前缀，默认 default-<序号>，每个未命名的缓存各不相同
+	OnRemove         OnRemove           // 条目被移除时的回调
+	MetricsRegistry  gometrics.Registry // 监控指标注册的位置，默认是 go-metrics 的 DefaultRegistry
+	SnapshotPath     string             // 快照文件的路径，为空表示不做后台快照
+	SnapshotInterval time.Duration      // 后台快照的间隔
+}
+
+func reloadOptions(options ...Option) *Options {
+	opts := new(Options)
+	for _, option := range options {
+		option(opts)
+	}
+	if opts.Name == "" { // 未命名的缓存各自使用不同的名字，避免指标互相覆盖
+		opts.Name = "default-" + strconv.FormatInt(atomic.AddInt64(&unnamedSeq, 1), 10)
+	}
+	if opts.MetricsRegistry == nil {
+		opts.MetricsRegistry = gometrics.DefaultRegistry
+	}
+	return opts
+}
+
+func WithName(name string) Option {
+	return func(opts *Options) {
+		opts.Name = name
+	}
+}
+
+func WithOnRemove(onRemove OnRemove) Option {
+	return func(opts *Options) {
+		opts.OnRemove = onRemove
+	}
+}
+
+func WithMetricsRegistry(registry gometrics.Registry) Option {
+	return func(opts *Options) {
+		opts.MetricsRegistry = registry
+	}
+}
+
+// 每隔interval把缓存快照到path，Close时再做最后一次快照
+// 创建缓存时如果path已经存在，会先从中恢复，用于进程重启之后的预热
+func WithSnapshot(path string, interval time.Duration) Option {
+	return func(opts *Options) {
+		opts.SnapshotPath = path
+		opts.SnapshotInterval = interval
+	}
+}
@@ -0,0 +1,96 @@
+package bigcache
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// RemoteStore 二级缓存（远端）的接口，比如redis、memcache
+// key不存在（或者已经过期）时，Get 返回 ErrNotFound
+type RemoteStore interface {
+	Get(ctx context.Context, key string) ([]byte, error)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	Del(ctx context.Context, key string) error
+}
+
+// Invalidator 失效通知，某个实例修改、删除了key之后，通知所有订阅的实例清掉自己的本地缓存
+// 生产环境通常基于redis的pub/sub等实现
+type Invalidator interface {
+	Publish(ctx context.Context, key string) error
+	// Subscribe 订阅失效通知，返回取消订阅的函数
+	Subscribe(fn func(key string)) (unsubscribe func())
+}
+
+// MemoryStore 进程内的 RemoteStore 和 Invalidator 实现，用于测试以及单机场景
+type MemoryStore struct {
+	mu      sync.RWMutex
+	items   map[string]memoryItem
+	subs    map[int]func(key string)
+	nextSub int
+}
+
+type memoryItem struct {
+	value    []byte
+	deadline int64
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		items: make(map[string]memoryItem),
+		subs:  make(map[int]func(key string)),
+	}
+}
+
+func (m *MemoryStore) Get(ctx context.Context, key string) ([]byte, error) {
+	m.mu.RLock()
+	item, ok := m.items[key]
+	m.mu.RUnlock()
+	if !ok || expired(item.deadline, time.Now().UnixNano()) {
+		return nil, ErrNotFound
+	}
+	return append([]byte(nil), item.value...), nil
+}
+
+func (m *MemoryStore) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.items[key] = memoryItem{
+		value:    append([]byte(nil), value...),
+		deadline: deadlineOf(ttl),
+	}
+	return nil
+}
+
+func (m *MemoryStore) Del(ctx context.Context, key string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	delete(m.items, key)
+	return nil
+}
+
+func (m *MemoryStore) Publish(ctx context.Context, key string) error {
+	m.mu.RLock()
+	subs := make([]func(key string), 0, len(m.subs))
+	for _, fn := range m.subs {
+		subs = append(subs, fn)
+	}
+	m.mu.RUnlock()
+	for _, fn := range subs {
+		fn(key)
+	}
+	return nil
+}
+
+func (m *MemoryStore) Subscribe(fn func(key string)) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	id := m.nextSub
+	m.nextSub++
+	m.subs[id] = fn
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.subs, id)
+	}
+}
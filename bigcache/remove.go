@@ -0,0 +1,116 @@
+package bigcache
+
+import (
+	"time"
+
+	"github.com/allegro/bigcache/v3"
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+// RemoveReason 条目被移除的原因
+type RemoveReason int
+
+const (
+	Expired RemoveReason = iota + 1 // 超过了全局的LifeWindow，或者单条目的TTL
+	NoSpace                         // 缓存已满（HardMaxCacheSize），最老的条目被淘汰
+	Deleted                         // 调用了Delete
+)
+
+func (r RemoveReason) String() string {
+	switch r {
+	case Expired:
+		return "expired"
+	case NoSpace:
+		return "nospace"
+	case Deleted:
+		return "deleted"
+	}
+	return "unknown"
+}
+
+// OnRemove 条目被移除时的回调
+// 注意：回调是在bigcache分片的锁内同步执行的，不能在回调中再读写同一个缓存，耗时的操作请自己异步处理
+type OnRemove func(key string, value []byte, reason RemoveReason)
+
+// 淘汰相关的监控指标，注册在 Options.MetricsRegistry 中，指标名以 bigcache.<name>. 为前缀：
+//
+//	evictions.expired  Counter  过期移除的条数
+//	evictions.nospace  Counter  空间不足被淘汰的条数
+//	evictions.deleted  Counter  被Delete的条数
+//
+// 缓存 Close 时注销这些指标
+type cacheMetrics struct {
+	registry   gometrics.Registry
+	registered map[string]interface{} // 完整指标名 -> 本缓存注册的指标
+
+	expired gometrics.Counter
+	noSpace gometrics.Counter
+	deleted gometrics.Counter
+}
+
+func newCacheMetrics(name string, r gometrics.Registry) *cacheMetrics {
+	prefix := "bigcache." + name + "."
+	m := &cacheMetrics{
+		registry:   r,
+		registered: make(map[string]interface{}),
+		expired:    gometrics.NewCounter(),
+		noSpace:    gometrics.NewCounter(),
+		deleted:    gometrics.NewCounter(),
+	}
+	// 显式指定了相同名字的缓存，指标以最后创建的为准
+	register := func(name string, metric interface{}) {
+		r.Unregister(prefix + name)
+		_ = r.Register(prefix+name, metric)
+		m.registered[prefix+name] = metric
+	}
+	register("evictions.expired", m.expired)
+	register("evictions.nospace", m.noSpace)
+	register("evictions.deleted", m.deleted)
+	return m
+}
+
+// 注销本缓存的指标，已经被后创建的同名缓存替换掉的不动
+func (m *cacheMetrics) unregister() {
+	for name, metric := range m.registered {
+		if m.registry.Get(name) == metric {
+			m.registry.Unregister(name)
+		}
+	}
+}
+
+// 挂到allegro/bigcache的 OnRemoveWithReason 上，负缓存条目是内部实现，不计数也不回调
+func (c *cache) onRemove(key string, entry []byte, reason bigcache.RemoveReason) {
+	kind, v, deadline, err := unwrapEntry(entry)
+	if err != nil || kind != entryValue {
+		return
+	}
+
+	var r RemoveReason
+	switch reason {
+	case bigcache.Expired:
+		r = Expired
+	case bigcache.NoSpace:
+		r = NoSpace
+	case bigcache.Deleted:
+		r = Deleted
+		// 单条目TTL到期之后是由清理协程调用Delete回收的，这里按过期统计
+		if expired(deadline, time.Now().UnixNano()) {
+			r = Expired
+		}
+	default:
+		return
+	}
+
+	switch r {
+	case Expired:
+		c.metrics.expired.Inc(1)
+	case NoSpace:
+		c.metrics.noSpace.Inc(1)
+	case Deleted:
+		c.metrics.deleted.Inc(1)
+	}
+	if c.opts.OnRemove != nil {
+		// entry指向分片内部的内存，回调返回之后可能被覆盖，这里复制一份
+		c.opts.OnRemove(key, append([]byte(nil), v...), r)
+	}
+}
@@ -0,0 +1,86 @@
+package bigcache
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	gometrics "github.com/rcrowley/go-metrics"
+)
+
+func TestOnRemove(t *testing.T) {
+	var mu sync.Mutex
+	removed := map[string]RemoveReason{}
+	registry := gometrics.NewRegistry()
+	c, err := NewCache(2, 1024, 256, time.Minute, 0,
+		WithName("test"),
+		WithMetricsRegistry(registry),
+		WithOnRemove(func(key string, value []byte, reason RemoveReason) {
+			mu.Lock()
+			defer mu.Unlock()
+			if string(value) != "v" {
+				t.Errorf("key:%v, value:%s", key, value)
+			}
+			removed[key] = reason
+		}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c.Close()
+
+	_ = c.Set("a", []byte("v"))
+	_ = c.SetWithTTL("b", []byte("v"), 10*time.Millisecond)
+	_ = c.Delete("a")
+	time.Sleep(20 * time.Millisecond)
+	c.(*cache).removeExpired()
+
+	mu.Lock()
+	if removed["a"] != Deleted || removed["b"] != Expired {
+		t.Errorf("removed:%v", removed)
+	}
+	mu.Unlock()
+
+	deleted := registry.Get("bigcache.test.evictions.deleted").(gometrics.Counter)
+	expired := registry.Get("bigcache.test.evictions.expired").(gometrics.Counter)
+	if deleted.Count() != 1 || expired.Count() != 1 {
+		t.Errorf("deleted:%v, expired:%v", deleted.Count(), expired.Count())
+	}
+}
+
+func TestCacheMetricsName(t *testing.T) {
+	registry := gometrics.NewRegistry()
+	newCache := func(opts ...Option) Cache {
+		c, err := NewCache(2, 1024, 256, time.Minute, 0, append(opts, WithMetricsRegistry(registry))...)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+	count := func() int {
+		n := 0
+		registry.Each(func(string, interface{}) { n++ })
+		return n
+	}
+
+	// 未命名的缓存各自注册，互不覆盖
+	c1, c2 := newCache(), newCache()
+	if c1.(*cache).opts.Name == c2.(*cache).opts.Name || count() != 6 {
+		t.Errorf("name1:%v, name2:%v, metrics:%v", c1.(*cache).opts.Name, c2.(*cache).opts.Name, count())
+	}
+	_ = c1.Close()
+	_ = c2.Close()
+	if count() != 0 {
+		t.Errorf("metrics:%v", count())
+	}
+
+	// 同名的缓存以最后创建的为准，先创建的关闭时不影响后创建的
+	old, c := newCache(WithName("same")), newCache(WithName("same"))
+	_ = old.Close()
+	if registry.Get("bigcache.same.evictions.deleted") == nil {
+		t.Error("metrics of the newer cache unregistered")
+	}
+	_ = c.Close()
+	if count() != 0 {
+		t.Errorf("metrics:%v", count())
+	}
+}
@@ -0,0 +1,210 @@
+package bigcache
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+	"log"
+	"os"
+	"time"
+)
+
+// 快照的格式：
+//
+//	header: | magic "BCSP"(4字节) | version(1字节) | 快照时间UnixNano(8字节) |
+//	record: | payload长度(4字节) | payload的crc32(4字节) | payload |
+//	payload: | key长度(4字节) | 剩余TTL纳秒(8字节，0表示不过期) | key | value |
+//	结尾:   | 0(4字节) | 0(4字节) |
+//
+// 每条记录单独校验，单条损坏时跳过该条继续恢复；没有读到结尾说明文件被截断
+const (
+	snapshotMagic      = "BCSP"
+	snapshotVersion    = 1
+	snapshotHeaderSize = 13
+	frameHeaderSize    = 8
+	payloadHeaderSize  = 12
+	maxFrameSize       = 1 << 30 // 超过这个长度，说明长度字段本身已经损坏
+)
+
+var (
+	// ErrCorruptSnapshot 快照的头部不合法，或者文件被截断
+	ErrCorruptSnapshot = errors.New("bigcache: corrupt snapshot")
+	// ErrSnapshotVersion 不支持的快照版本
+	ErrSnapshotVersion = errors.New("bigcache: unsupported snapshot version")
+)
+
+// Snapshot 把所有未过期的条目写入w，负缓存条目不会写入
+// 剩余TTL取单独TTL和全局LifeWindow中先到期的那个，恢复之后不会重新计时，反复重启也不会延长条目的寿命
+func (c *cache) Snapshot(w io.Writer) error {
+	bw := bufio.NewWriter(w)
+	now := time.Now().UnixNano()
+
+	header := make([]byte, snapshotHeaderSize)
+	copy(header, snapshotMagic)
+	header[4] = snapshotVersion
+	binary.LittleEndian.PutUint64(header[5:], uint64(now))
+	if _, err := bw.Write(header); err != nil {
+		return err
+	}
+
+	var frame [frameHeaderSize]byte
+	it := c.cache.Iterator()
+	for it.SetNext() {
+		info, err := it.Value()
+		if err != nil {
+			continue
+		}
+		kind, v, deadline, err := unwrapEntry(info.Value())
+		if err != nil || kind != entryValue || expired(deadline, now) {
+			continue
+		}
+		var ttl int64
+		if deadline > 0 {
+			ttl = deadline - now
+		}
+		if c.lifeWindow > 0 { // bigcache的时间戳精确到秒
+			life := int64(c.lifeWindow) - (now - int64(info.Timestamp())*int64(time.Second))
+			if life <= 0 {
+				continue
+			}
+			if ttl == 0 || life < ttl {
+				ttl = life
+			}
+		}
+		key := info.Key()
+		payload := make([]byte, payloadHeaderSize+len(key)+len(v))
+		binary.LittleEndian.PutUint32(payload, uint32(len(key)))
+		binary.LittleEndian.PutUint64(payload[4:], uint64(ttl))
+		copy(payload[payloadHeaderSize:], key)
+		copy(payload[payloadHeaderSize+len(key):], v)
+
+		binary.LittleEndian.PutUint32(frame[:], uint32(len(payload)))
+		binary.LittleEndian.PutUint32(frame[4:], crc32.ChecksumIEEE(payload))
+		if _, err := bw.Write(frame[:]); err != nil {
+			return err
+		}
+		if _, err := bw.Write(payload); err != nil {
+			return err
+		}
+	}
+
+	if _, err := bw.Write(make([]byte, frameHeaderSize)); err != nil {
+		return err
+	}
+	return bw.Flush()
+}
+
+// Restore 从r中恢复条目，返回成功恢复的条数，已有的条目不会被清空
+// 校验失败、已经过期、或者写入失败（比如超过MaxEntrySize）的记录会被跳过
+// 快照被截断时，返回已经恢复的条数以及 ErrCorruptSnapshot
+func (c *cache) Restore(r io.Reader) (int, error) {
+	br := bufio.NewReader(r)
+	header := make([]byte, snapshotHeaderSize)
+	if _, err := io.ReadFull(br, header); err != nil {
+		return 0, ErrCorruptSnapshot
+	}
+	if string(header[:4]) != snapshotMagic {
+		return 0, ErrCorruptSnapshot
+	}
+	if header[4] != snapshotVersion {
+		return 0, fmt.Errorf("%w: %d", ErrSnapshotVersion, header[4])
+	}
+	elapsed := time.Now().UnixNano() - int64(binary.LittleEndian.Uint64(header[5:]))
+
+	loaded := 0
+	var frame [frameHeaderSize]byte
+	for {
+		if _, err := io.ReadFull(br, frame[:]); err != nil {
+			return loaded, ErrCorruptSnapshot
+		}
+		size := binary.LittleEndian.Uint32(frame[:])
+		if size == 0 {
+			return loaded, nil
+		}
+		if size < payloadHeaderSize || size > maxFrameSize {
+			return loaded, ErrCorruptSnapshot
+		}
+		payload := make([]byte, size)
+		if _, err := io.ReadFull(br, payload); err != nil {
+			return loaded, ErrCorruptSnapshot
+		}
+		if crc32.ChecksumIEEE(payload) != binary.LittleEndian.Uint32(frame[4:]) {
+			continue
+		}
+
+		keyLen := binary.LittleEndian.Uint32(payload)
+		if keyLen > size-payloadHeaderSize {
+			continue
+		}
+		ttl := int64(binary.LittleEndian.Uint64(payload[4:]))
+		if ttl > 0 {
+			if ttl -= elapsed; ttl <= 0 {
+				continue
+			}
+		}
+		key := string(payload[payloadHeaderSize : payloadHeaderSize+keyLen])
+		value := payload[payloadHeaderSize+keyLen:]
+		if err := c.SetWithTTL(key, value, time.Duration(ttl)); err != nil {
+			continue
+		}
+		loaded++
+	}
+}
+
+// 快照到文件，先写临时文件再rename，避免进程中途退出留下半个快照
+func (c *cache) snapshotToFile(path string) error {
+	c.snapMu.Lock()
+	defer c.snapMu.Unlock()
+
+	tmp := path + ".tmp"
+	f, err := os.Create(tmp)
+	if err != nil {
+		return err
+	}
+	if err := c.Snapshot(f); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Sync(); err != nil {
+		f.Close()
+		os.Remove(tmp)
+		return err
+	}
+	if err := f.Close(); err != nil {
+		os.Remove(tmp)
+		return err
+	}
+	return os.Rename(tmp, path)
+}
+
+func (c *cache) restoreFromFile(path string) (int, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return 0, nil
+		}
+		return 0, err
+	}
+	defer f.Close()
+	return c.Restore(f)
+}
+
+// 后台定期快照
+func (c *cache) snapshotLoop(path string, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-c.quit:
+			return
+		case <-ticker.C:
+			if err := c.snapshotToFile(path); err != nil {
+				log.Printf("bigcache: snapshot to %s failed: %v", path, err)
+			}
+		}
+	}
+}
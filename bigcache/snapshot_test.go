@@ -0,0 +1,128 @@
+package bigcache
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSnapshot(t *testing.T) {
+	c, err := NewCache(2, 1024, 256, time.Minute, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Set("a", []byte("1"))
+	_ = c.SetWithTTL("b", []byte("2"), time.Minute)
+	_ = c.SetWithTTL("c", []byte("3"), 20*time.Millisecond)
+
+	var buf bytes.Buffer
+	if err := c.Snapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+	data := buf.Bytes()
+
+	// c在恢复之前已经过期
+	time.Sleep(30 * time.Millisecond)
+	c2, _ := NewCache(2, 1024, 256, time.Minute, time.Minute)
+	n, err := c2.Restore(bytes.NewReader(data))
+	if err != nil || n != 2 {
+		t.Fatalf("n:%v, err:%v", n, err)
+	}
+	for k, want := range map[string]string{"a": "1", "b": "2"} {
+		if v, err := c2.Get(k); err != nil || string(v) != want {
+			t.Errorf("k:%v, v:%s, err:%v", k, v, err)
+		}
+	}
+	if _, err := c2.Get("c"); err != ErrNotFound {
+		t.Errorf("err:%v", err)
+	}
+
+	// 损坏一条记录的value，只跳过这一条
+	bad := append([]byte(nil), data...)
+	bad[len(bad)-frameHeaderSize-1] ^= 0xff
+	c3, _ := NewCache(2, 1024, 256, time.Minute, time.Minute)
+	if n, err := c3.Restore(bytes.NewReader(bad)); err != nil || n != 1 {
+		t.Errorf("n:%v, err:%v", n, err)
+	}
+
+	// 截断
+	c4, _ := NewCache(2, 1024, 256, time.Minute, time.Minute)
+	if _, err := c4.Restore(bytes.NewReader(data[:len(data)-3])); err != ErrCorruptSnapshot {
+		t.Errorf("err:%v", err)
+	}
+	// 版本不对
+	bad = append([]byte(nil), data...)
+	bad[4] = 99
+	if _, err := c4.Restore(bytes.NewReader(bad)); err == nil {
+		t.Errorf("want version error")
+	}
+}
+
+func TestSnapshotLifeWindow(t *testing.T) {
+	c, err := NewCache(2, 1024, 256, time.Minute, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Set("a", []byte("1"))
+	var buf bytes.Buffer
+	if err := c.Snapshot(&buf); err != nil {
+		t.Fatal(err)
+	}
+
+	// 没有单独TTL的条目，写入的是LifeWindow的剩余时间（时间戳精确到秒）
+	ttl := time.Duration(binary.LittleEndian.Uint64(buf.Bytes()[snapshotHeaderSize+frameHeaderSize+4:]))
+	if ttl <= time.Minute-2*time.Second || ttl > time.Minute {
+		t.Errorf("ttl:%v", ttl)
+	}
+
+	// 恢复之后带上剩余的TTL，不会重新获得完整的LifeWindow
+	c2, _ := NewCache(2, 1024, 256, time.Hour, 0)
+	if n, err := c2.Restore(bytes.NewReader(buf.Bytes())); err != nil || n != 1 {
+		t.Fatalf("n:%v, err:%v", n, err)
+	}
+	b, err := c2.(*cache).cache.Get("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, _, deadline, _ := unwrapEntry(b)
+	if left := time.Duration(deadline - time.Now().UnixNano()); left <= 0 || left > time.Minute {
+		t.Errorf("left:%v", left)
+	}
+}
+
+func TestSnapshotFile(t *testing.T) {
+	dir, err := ioutil.TempDir("", "bigcache")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, "cache.snap")
+
+	c, err := NewCache(2, 1024, 256, time.Minute, time.Minute, WithSnapshot(path, 20*time.Millisecond))
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = c.Set("haha", []byte("v"))
+	time.Sleep(50 * time.Millisecond)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("snapshot not written: %v", err)
+	}
+	_ = c.Set("hehe", []byte("v"))
+	// Close时做最后一次快照
+	if err := c.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	c2, err := NewCache(2, 1024, 256, time.Minute, time.Minute, WithSnapshot(path, 0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer c2.Close()
+	if c2.Len() != 2 {
+		t.Errorf("len:%v", c2.Len())
+	}
+}
@@ -0,0 +1,91 @@
+package bigcache
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// TieredCache 两级缓存：L1是本地的bigcache，L2是远端的 RemoteStore
+// 读的时候先查L1，未命中再查L2，L2命中则回填L1；写、删的时候先操作L2，再操作L1，并发出失效通知
+type TieredCache struct {
+	local       Cache
+	remote      RemoteStore
+	localTTL    time.Duration // 回填L1时的TTL，通常比L2的TTL短，用来限制本地数据不一致的时间
+	invalidator Invalidator
+	unsubscribe func()
+}
+
+// NewTieredCache 创建两级缓存，invalidator 为nil时不做跨实例的失效通知
+func NewTieredCache(local Cache, remote RemoteStore, localTTL time.Duration, invalidator Invalidator) *TieredCache {
+	t := &TieredCache{
+		local:       local,
+		remote:      remote,
+		localTTL:    localTTL,
+		invalidator: invalidator,
+	}
+	if invalidator != nil {
+		t.unsubscribe = invalidator.Subscribe(func(key string) {
+			_ = local.Delete(key)
+		})
+	}
+	return t
+}
+
+// Get key在两级缓存中都不存在时返回 ErrNotFound
+func (t *TieredCache) Get(ctx context.Context, key string) ([]byte, error) {
+	v, err := t.local.Get(key)
+	if err == nil {
+		return v, nil
+	}
+	if !errors.Is(err, ErrNotFound) {
+		return nil, err
+	}
+
+	v, err = t.remote.Get(ctx, key)
+	if err != nil {
+		return nil, err
+	}
+	_ = t.local.SetWithTTL(key, v, t.localTTL) // 回填失败不影响本次读取
+	return v, nil
+}
+
+// Set 写入两级缓存，ttl 是L2的TTL，L1使用 localTTL 和 ttl 中较短的那个
+func (t *TieredCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) error {
+	if err := t.remote.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	if err := t.publish(ctx, key); err != nil {
+		return err
+	}
+	localTTL := t.localTTL
+	if ttl > 0 && (localTTL <= 0 || ttl < localTTL) {
+		localTTL = ttl
+	}
+	return t.local.SetWithTTL(key, value, localTTL)
+}
+
+// Delete 从两级缓存中删除，并通知其他实例清掉本地缓存
+func (t *TieredCache) Delete(ctx context.Context, key string) error {
+	if err := t.remote.Del(ctx, key); err != nil {
+		return err
+	}
+	if err := t.local.Delete(key); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return t.publish(ctx, key)
+}
+
+// Close 取消失效通知的订阅，不会关闭L1和L2
+func (t *TieredCache) Close() {
+	if t.unsubscribe != nil {
+		t.unsubscribe()
+	}
+}
+
+func (t *TieredCache) publish(ctx context.Context, key string) error {
+	if t.invalidator == nil {
+		return nil
+	}
+	return t.invalidator.Publish(ctx, key)
+}
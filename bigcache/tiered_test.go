@@ -0,0 +1,77 @@
+package bigcache
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestTieredCache(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	newTiered := func() (*TieredCache, Cache) {
+		c, err := NewCache(2, 1024, 256, time.Minute, time.Minute)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return NewTieredCache(c, store, time.Minute, store), c
+	}
+	a, la := newTiered()
+	b, lb := newTiered()
+	defer a.Close()
+	defer b.Close()
+
+	// L2命中之后回填L1
+	_ = store.Set(ctx, "haha", []byte("v1"), time.Minute)
+	if v, err := b.Get(ctx, "haha"); err != nil || string(v) != "v1" {
+		t.Errorf("v:%s, err:%v", v, err)
+	}
+	if v, err := lb.Get("haha"); err != nil || string(v) != "v1" {
+		t.Errorf("v:%s, err:%v", v, err)
+	}
+
+	// a写入新值，b的本地缓存被清掉，再读拿到新值
+	if err := a.Set(ctx, "haha", []byte("v2"), time.Minute); err != nil {
+		t.Fatal(err)
+	}
+	if v, err := la.Get("haha"); err != nil || string(v) != "v2" {
+		t.Errorf("v:%s, err:%v", v, err)
+	}
+	if _, err := lb.Get("haha"); err != ErrNotFound {
+		t.Errorf("err:%v", err)
+	}
+	if v, err := b.Get(ctx, "haha"); err != nil || string(v) != "v2" {
+		t.Errorf("v:%s, err:%v", v, err)
+	}
+
+	// 删除之后所有实例都读不到
+	if err := a.Delete(ctx, "haha"); err != nil {
+		t.Fatal(err)
+	}
+	for _, tc := range []*TieredCache{a, b} {
+		if _, err := tc.Get(ctx, "haha"); err != ErrNotFound {
+			t.Errorf("err:%v", err)
+		}
+	}
+
+	// 取消订阅之后不再收到失效通知
+	b.Close()
+	_ = b.Set(ctx, "hehe", []byte("v1"), time.Minute)
+	_ = a.Set(ctx, "hehe", []byte("v2"), time.Minute)
+	if v, err := lb.Get("hehe"); err != nil || string(v) != "v1" {
+		t.Errorf("v:%s, err:%v", v, err)
+	}
+}
+
+func TestMemoryStoreTTL(t *testing.T) {
+	ctx := context.Background()
+	store := NewMemoryStore()
+	_ = store.Set(ctx, "haha", []byte("v"), 20*time.Millisecond)
+	if v, err := store.Get(ctx, "haha"); err != nil || string(v) != "v" {
+		t.Errorf("v:%s, err:%v", v, err)
+	}
+	time.Sleep(30 * time.Millisecond)
+	if _, err := store.Get(ctx, "haha"); err != ErrNotFound {
+		t.Errorf("err:%v", err)
+	}
+}
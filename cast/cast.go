@@ -3,6 +3,9 @@
  * Cast强制转换提供了一些ToXXX 的方法。这些方法将始终返回所需的类型。如果提供的输入不能转换为该类型，则返回该类型的0或nil值。
  * Cast也提供了 ToXXXE相同的方法。这些方法返回与ToXXX方法相同的结果，外加一个额外的错误，告诉您是否成功转换。
  * 使用这些方法，您可以分辨输入匹配零值时的不同，以及转换失败时返回零值时的不同。
+ *
+ * 本包在cast之上提供了同样风格的 ToXXX/ToXXXE，默认是宽松模式（Lenient），行为与cast一致；
+ * 严格模式（Strict）会拒绝有损的转换（比如 ToInt(8.31)）、溢出和无法解析的字符串，见 Converter。
  */
 package cast
 
@@ -0,0 +1,43 @@
+package cast
+
+import "reflect"
+
+// ToSliceOfE 转换为元素类型为elemType的slice，返回值可以直接断言为 []elemType
+// 支持slice、数组、逗号分隔的字符串（"a, b, c"）以及JSON数组字符串（"[1, 2, 3]"），每个元素按转换器的模式转换：
+//
+//	v, err := cast.ToSliceOfE("1, 2, 3", reflect.TypeOf(0))
+//	ints := v.([]int)
+func (c *Converter) ToSliceOfE(i interface{}, elemType reflect.Type) (interface{}, error) {
+	return c.ToE(i, reflect.SliceOf(elemType))
+}
+
+func (c *Converter) ToSliceOf(i interface{}, elemType reflect.Type) interface{} {
+	v, _ := c.ToSliceOfE(i, elemType)
+	return v
+}
+
+// ToStringMapOfE 转换为 map[string]valueType，支持任意key类型的map以及JSON对象字符串
+func (c *Converter) ToStringMapOfE(i interface{}, valueType reflect.Type) (interface{}, error) {
+	return c.ToE(i, reflect.MapOf(typeString, valueType))
+}
+
+func (c *Converter) ToStringMapOf(i interface{}, valueType reflect.Type) interface{} {
+	v, _ := c.ToStringMapOfE(i, valueType)
+	return v
+}
+
+func ToSliceOf(i interface{}, elemType reflect.Type, mode ...Mode) interface{} {
+	return converterOf(mode).ToSliceOf(i, elemType)
+}
+
+func ToSliceOfE(i interface{}, elemType reflect.Type, mode ...Mode) (interface{}, error) {
+	return converterOf(mode).ToSliceOfE(i, elemType)
+}
+
+func ToStringMapOf(i interface{}, valueType reflect.Type, mode ...Mode) interface{} {
+	return converterOf(mode).ToStringMapOf(i, valueType)
+}
+
+func ToStringMapOfE(i interface{}, valueType reflect.Type, mode ...Mode) (interface{}, error) {
+	return converterOf(mode).ToStringMapOfE(i, valueType)
+}
@@ -0,0 +1,172 @@
+package cast
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Mode 转换模式
+type Mode int
+
+const (
+	// Lenient 宽松模式，与spf13/cast的行为一致，比如 ToInt(8.31) 得到8，溢出时直接截断
+	Lenient Mode = iota
+	// Strict 严格模式，拒绝有损的转换（小数转整数）、溢出，以及无法解析的字符串
+	Strict
+)
+
+func (m Mode) String() string {
+	switch m {
+	case Lenient:
+		return "lenient"
+	case Strict:
+		return "strict"
+	}
+	return "unknown"
+}
+
+var (
+	// ErrLossy 转换会丢失信息，比如 8.31 转 int
+	ErrLossy = errors.New("lossy conversion")
+	// ErrOverflow 超出了目标类型的范围
+	ErrOverflow = errors.New("value out of range")
+	// ErrSyntax 字符串无法解析为目标类型
+	ErrSyntax = errors.New("invalid syntax")
+	// ErrUnsupported 不支持从该类型转换
+	ErrUnsupported = errors.New("unsupported type")
+)
+
+// CastError 转换失败的错误，记录了源类型、目标类型和值，可以用 errors.Is 判断具体原因：
+//
+//	_, err := cast.ToIntE(8.31, cast.Strict)
+//	errors.Is(err, cast.ErrLossy) // true
+type CastError struct {
+	From  string      // 源类型
+	To    string      // 目标类型
+	Value interface{} // 源值
+	Err   error
+}
+
+func (e *CastError) Error() string {
+	return fmt.Sprintf("cast: unable to cast %#v of type %s to %s: %v", e.Value, e.From, e.To, e.Err)
+}
+
+func (e *CastError) Unwrap() error {
+	return e.Err
+}
+
+// 包装转换的错误，err为nil时返回nil
+func castError(i interface{}, to string, err error) error {
+	if err == nil {
+		return nil
+	}
+	var ce *CastError
+	if errors.As(err, &ce) {
+		return err
+	}
+	return &CastError{From: fmt.Sprintf("%T", i), To: to, Value: i, Err: err}
+}
+
+type Option func(opts *Options)
+
+type Options struct {
+	Mode        Mode           // 转换模式，默认 Lenient
+	Location    *time.Location // 解析没有时区信息的时间、转换时间戳时使用的时区，默认UTC
+	TimeLayouts []string       // 解析时间时依次尝试的格式，默认 DefaultTimeLayouts
+}
+
+func reloadOptions(options ...Option) *Options {
+	opts := new(Options)
+	for _, option := range options {
+		option(opts)
+	}
+	if opts.Location == nil {
+		opts.Location = time.UTC
+	}
+	if len(opts.TimeLayouts) == 0 {
+		opts.TimeLayouts = DefaultTimeLayouts
+	}
+	return opts
+}
+
+func WithMode(mode Mode) Option {
+	return func(opts *Options) {
+		opts.Mode = mode
+	}
+}
+
+func WithLocation(loc *time.Location) Option {
+	return func(opts *Options) {
+		opts.Location = loc
+	}
+}
+
+func WithTimeLayouts(layouts ...string) Option {
+	return func(opts *Options) {
+		opts.TimeLayouts = layouts
+	}
+}
+
+// Converter 类型转换器，不同的实例可以有不同的配置，并发安全
+// 包级别的 ToXXX/ToXXXE 使用默认的宽松模式转换器，也可以在单次调用时指定模式：
+//
+//	cast.ToIntE("8", cast.Strict)
+type Converter struct {
+	opts     *Options
+	registry *registry // 自定义的转换函数，见 Register
+	builtin  bool      // 只使用内置的转换，忽略所有注册的转换函数，见 Builtin
+}
+
+func NewConverter(options ...Option) *Converter {
+	return &Converter{opts: reloadOptions(options...), registry: newRegistry()}
+}
+
+// Mode 转换器的模式
+func (c *Converter) Mode() Mode {
+	return c.opts.Mode
+}
+
+// WithMode 返回一个指定模式的转换器，其他配置不变（共享注册的转换函数），原转换器不受影响
+func (c *Converter) WithMode(mode Mode) *Converter {
+	if mode == c.opts.Mode {
+		return c
+	}
+	opts := *c.opts
+	opts.Mode = mode
+	return &Converter{opts: &opts, registry: c.registry, builtin: c.builtin}
+}
+
+// Builtin 返回一个只使用内置转换的转换器，其他配置不变，忽略当前转换器和全局注册的转换函数
+// 用于在自定义的转换函数中回退到内置的转换，见 Register
+func (c *Converter) Builtin() *Converter {
+	if c.builtin {
+		return c
+	}
+	return &Converter{opts: c.opts, registry: c.registry, builtin: true}
+}
+
+var (
+	lenientConverter = NewConverter(WithMode(Lenient))
+	strictConverter  = NewConverter(WithMode(Strict))
+	lenientBuiltin   = lenientConverter.Builtin()
+	strictBuiltin    = strictConverter.Builtin()
+)
+
+// 包级别函数使用的转换器，mode最多取第一个
+func converterOf(mode []Mode) *Converter {
+	if len(mode) > 0 && mode[0] == Strict {
+		return strictConverter
+	}
+	return lenientConverter
+}
+
+// Builtin 返回只使用内置转换的默认转换器，mode最多取第一个，比如：
+//
+//	cast.Builtin(cast.Strict).ToBoolE(v)
+func Builtin(mode ...Mode) *Converter {
+	if len(mode) > 0 && mode[0] == Strict {
+		return strictBuiltin
+	}
+	return lenientBuiltin
+}
@@ -0,0 +1,96 @@
+package cast
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLenient(t *testing.T) {
+	if v := ToInt(8.31); v != 8 {
+		t.Errorf("v:%v", v)
+	}
+	if v := ToInt8(300); v != 44 {
+		t.Errorf("v:%v", v)
+	}
+	if v, err := ToIntE("abc"); v != 0 || err == nil {
+		t.Errorf("v:%v, err:%v", v, err)
+	}
+	if v := ToDuration("5"); v != 5 {
+		t.Errorf("v:%v", v)
+	}
+}
+
+func TestStrict(t *testing.T) {
+	cases := []struct {
+		name string
+		fn   func() (interface{}, error)
+		want interface{}
+		err  error
+	}{
+		{"int", func() (interface{}, error) { return ToIntE("8", Strict) }, 8, nil},
+		{"int hex", func() (interface{}, error) { return ToIntE("0x10", Strict) }, 16, nil},
+		{"int float", func() (interface{}, error) { return ToIntE(8.0, Strict) }, 8, nil},
+		{"int lossy", func() (interface{}, error) { return ToIntE(8.31, Strict) }, 0, ErrLossy},
+		{"int nan", func() (interface{}, error) { return ToIntE(math.NaN(), Strict) }, 0, ErrLossy},
+		{"int syntax", func() (interface{}, error) { return ToIntE("abc", Strict) }, 0, ErrSyntax},
+		{"int8 overflow", func() (interface{}, error) { return ToInt8E(300, Strict) }, int8(0), ErrOverflow},
+		{"int8 min", func() (interface{}, error) { return ToInt8E(-128.0, Strict) }, int8(-128), nil},
+		{"int8 string overflow", func() (interface{}, error) { return ToInt8E("128", Strict) }, int8(0), ErrOverflow},
+		{"int64 uint overflow", func() (interface{}, error) { return ToInt64E(uint64(math.MaxUint64), Strict) }, int64(0), ErrOverflow},
+		{"int64 float overflow", func() (interface{}, error) { return ToInt64E(1e19, Strict) }, int64(0), ErrOverflow},
+		{"uint negative", func() (interface{}, error) { return ToUintE(-1, Strict) }, uint(0), ErrOverflow},
+		{"uint64 max", func() (interface{}, error) { return ToUint64E("18446744073709551615", Strict) }, uint64(math.MaxUint64), nil},
+		{"uint8 overflow", func() (interface{}, error) { return ToUint8E(256, Strict) }, uint8(0), ErrOverflow},
+		{"float", func() (interface{}, error) { return ToFloat64E("8.31", Strict) }, 8.31, nil},
+		{"float lossy", func() (interface{}, error) { return ToFloat64E(int64(1)<<60+1, Strict) }, 0.0, ErrLossy},
+		{"float32 overflow", func() (interface{}, error) { return ToFloat32E(1e40, Strict) }, float32(0), ErrOverflow},
+		{"float32 exact", func() (interface{}, error) { return ToFloat32E(16777216, Strict) }, float32(16777216), nil},
+		{"float32 lossy", func() (interface{}, error) { return ToFloat32E(16777217, Strict) }, float32(0), ErrLossy},
+		{"float32 uint lossy", func() (interface{}, error) { return ToFloat32E(uint32(16777217), Strict) }, float32(0), ErrLossy},
+		{"float32 string lossy", func() (interface{}, error) { return ToFloat32E("16777217", Strict) }, float32(0), ErrLossy},
+		{"float32 string", func() (interface{}, error) { return ToFloat32E("-16777216", Strict) }, float32(-16777216), nil},
+		{"float32 decimal", func() (interface{}, error) { return ToFloat32E("8.5", Strict) }, float32(8.5), nil},
+		{"float string lossy", func() (interface{}, error) { return ToFloat64E("9007199254740993", Strict) }, 0.0, ErrLossy},
+		{"float syntax", func() (interface{}, error) { return ToFloat64E("8.31x", Strict) }, 0.0, ErrSyntax},
+		{"json number", func() (interface{}, error) { return ToIntE(json.Number("42"), Strict) }, 42, nil},
+		{"named type", func() (interface{}, error) { return ToInt64E(time.Second, Strict) }, int64(time.Second), nil},
+		{"pointer", func() (interface{}, error) { v := 3.0; return ToIntE(&v, Strict) }, 3, nil},
+		{"bool", func() (interface{}, error) { return ToBoolE("true", Strict) }, true, nil},
+		{"bool number", func() (interface{}, error) { return ToBoolE(1, Strict) }, true, nil},
+		{"bool lossy", func() (interface{}, error) { return ToBoolE(2, Strict) }, false, ErrLossy},
+		{"bool syntax", func() (interface{}, error) { return ToBoolE("yes", Strict) }, false, ErrSyntax},
+		{"duration", func() (interface{}, error) { return ToDurationE("1.5s", Strict) }, 1500 * time.Millisecond, nil},
+		{"duration no unit", func() (interface{}, error) { return ToDurationE("5", Strict) }, time.Duration(0), ErrSyntax},
+		{"unsupported", func() (interface{}, error) { return ToIntE([]int{1}, Strict) }, 0, ErrUnsupported},
+	}
+	for _, c := range cases {
+		v, err := c.fn()
+		if v != c.want || !errors.Is(err, c.err) {
+			t.Errorf("%s: v:%v, err:%v", c.name, v, err)
+		}
+	}
+}
+
+func TestConverterMode(t *testing.T) {
+	c := NewConverter(WithMode(Strict))
+	_, err := c.ToIntE(8.31)
+	var ce *CastError
+	if !errors.As(err, &ce) || ce.From != "float64" || ce.To != "int" || ce.Value != 8.31 {
+		t.Fatalf("err:%v", err)
+	}
+	if !strings.Contains(err.Error(), "8.31") || !strings.Contains(err.Error(), "float64") {
+		t.Errorf("err:%v", err)
+	}
+
+	// 单独切换模式，不影响原来的转换器
+	if v, err := c.WithMode(Lenient).ToIntE(8.31); v != 8 || err != nil {
+		t.Errorf("v:%v, err:%v", v, err)
+	}
+	if c.Mode() != Strict {
+		t.Errorf("mode:%v", c.Mode())
+	}
+}
@@ -0,0 +1,367 @@
+package cast
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ErrDecodeTarget Decode 的out不是指向结构体的非nil指针
+var ErrDecodeTarget = errors.New("cast: decode target must be a non-nil pointer to struct")
+
+// FieldError 单个字段的解码错误，Path 是字段的完整路径，比如 servers[2].port
+type FieldError struct {
+	Path string
+	Err  error
+}
+
+func (e *FieldError) Error() string {
+	return e.Path + ": " + e.Err.Error()
+}
+
+func (e *FieldError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeError 解码过程中所有字段的错误，不会遇到第一个错误就停止
+type DecodeError struct {
+	Errors []*FieldError
+}
+
+func (e *DecodeError) Error() string {
+	msgs := make([]string, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		msgs = append(msgs, err.Error())
+	}
+	return fmt.Sprintf("cast: %d error(s) decoding: %s", len(e.Errors), strings.Join(msgs, "; "))
+}
+
+// Is 任意一个字段的错误匹配target即可，比如 errors.Is(err, cast.ErrOverflow)
+func (e *DecodeError) Is(target error) bool {
+	for _, err := range e.Errors {
+		if errors.Is(err, target) {
+			return true
+		}
+	}
+	return false
+}
+
+// Decode 把松散类型的map（比如json、yaml解析出来的配置）解码到结构体中，out必须是指向结构体的指针
+// 字段名取自 cast 标签，没有标签时按字段名匹配（不区分大小写），标签为 "-" 的字段忽略，
+// 没有标签的匿名结构体字段会被展开到上一层。每个值都按转换器的模式做类型转换，支持嵌套的结构体、
+// slice、map、指针，time.Duration 和 time.Time（见 ToTimeE）可以从字符串解析，slice可以从逗号分隔的字符串
+// 或者JSON数组字符串解析，map可以从JSON对象字符串解析。input中不存在的字段保持原值。
+// 所有字段的错误都会收集到 *DecodeError 中一起返回：
+//
+//	type Server struct {
+//		Host string `cast:"host"`
+//		Port int    `cast:"port"`
+//	}
+//	type Config struct {
+//		Servers []Server      `cast:"servers"`
+//		Timeout time.Duration `cast:"timeout"`
+//	}
+func (c *Converter) Decode(input map[string]interface{}, out interface{}) error {
+	v := reflect.ValueOf(out)
+	if v.Kind() != reflect.Ptr || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ErrDecodeTarget
+	}
+	d := &decoder{c: c}
+	d.decodeStruct("", input, v.Elem())
+	if len(d.errs) > 0 {
+		return &DecodeError{Errors: d.errs}
+	}
+	return nil
+}
+
+func Decode(input map[string]interface{}, out interface{}, mode ...Mode) error {
+	return converterOf(mode).Decode(input, out)
+}
+
+type decoder struct {
+	c    *Converter
+	errs []*FieldError
+}
+
+func (d *decoder) fail(path string, err error) {
+	d.errs = append(d.errs, &FieldError{Path: path, Err: err})
+}
+
+// 将in解码到v中，v必须是可以Set的
+func (d *decoder) decode(path string, in interface{}, v reflect.Value) {
+	t := v.Type()
+	if r, ok, err := d.c.custom(in, t); ok {
+		if err != nil {
+			d.fail(path, err)
+			return
+		}
+		if r == nil { // 目标是接口类型时，零值就是nil，reflect.ValueOf(nil)不能用来Set
+			v.Set(reflect.Zero(t))
+			return
+		}
+		v.Set(reflect.ValueOf(r))
+		return
+	}
+	if in == nil {
+		return
+	}
+	switch t {
+	case typeTime:
+		tm, err := d.c.ToTimeE(in)
+		if err != nil {
+			d.fail(path, err)
+			return
+		}
+		v.Set(reflect.ValueOf(tm))
+		return
+	case typeDuration:
+		dur, err := d.c.ToDurationE(in)
+		if err != nil {
+			d.fail(path, err)
+			return
+		}
+		v.SetInt(int64(dur))
+		return
+	}
+
+	switch t.Kind() {
+	case reflect.Ptr:
+		// 先解码到新的值中，成功之后再赋值，失败时保持原值
+		n := len(d.errs)
+		elem := reflect.New(t.Elem())
+		d.decode(path, in, elem.Elem())
+		if len(d.errs) == n {
+			v.Set(elem)
+		}
+	case reflect.Interface:
+		rv := reflect.ValueOf(in)
+		if !rv.Type().AssignableTo(t) {
+			d.fail(path, castError(in, t.String(), ErrUnsupported))
+			return
+		}
+		v.Set(rv)
+	case reflect.Struct:
+		m, ok := toStringMap(in)
+		if !ok {
+			d.fail(path, castError(in, t.String(), ErrUnsupported))
+			return
+		}
+		d.decodeStruct(path, m, v)
+	case reflect.Slice, reflect.Array:
+		d.decodeSlice(path, in, v)
+	case reflect.Map:
+		d.decodeMap(path, in, v)
+	default:
+		rv, err := d.scalar(in, t)
+		if err != nil {
+			d.fail(path, err)
+			return
+		}
+		v.Set(rv)
+	}
+}
+
+func (d *decoder) decodeStruct(path string, m map[string]interface{}, v reflect.Value) {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		tag := f.Tag.Get("cast")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if name == "" && f.Anonymous && f.Type.Kind() == reflect.Struct {
+			d.decodeStruct(path, m, v.Field(i))
+			continue
+		}
+		if f.PkgPath != "" { // 未导出的字段
+			continue
+		}
+
+		in, ok := m[name]
+		if name == "" {
+			name = f.Name
+			in, ok = lookupFold(m, name)
+		}
+		if !ok {
+			continue
+		}
+		child := name
+		if path != "" {
+			child = path + "." + name
+		}
+		d.decode(child, in, v.Field(i))
+	}
+}
+
+func (d *decoder) decodeSlice(path string, in interface{}, v reflect.Value) {
+	t := v.Type()
+	// []byte可以直接从字符串得到
+	if s, ok := in.(string); ok && t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Uint8 {
+		v.SetBytes([]byte(s))
+		return
+	}
+	if s, ok := indirect(in).(string); ok {
+		items, err := splitList(s)
+		if err != nil {
+			d.fail(path, castError(in, t.String(), err))
+			return
+		}
+		in = items
+	}
+	rv := reflect.ValueOf(indirect(in))
+	if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+		d.fail(path, castError(in, t.String(), ErrUnsupported))
+		return
+	}
+
+	n := rv.Len()
+	var out reflect.Value
+	if t.Kind() == reflect.Array {
+		if n > t.Len() {
+			d.fail(path, castError(in, t.String(), ErrOverflow))
+			return
+		}
+		out = reflect.New(t).Elem()
+	} else {
+		out = reflect.MakeSlice(t, n, n)
+	}
+	for i := 0; i < n; i++ {
+		d.decode(path+"["+strconv.Itoa(i)+"]", rv.Index(i).Interface(), out.Index(i))
+	}
+	v.Set(out)
+}
+
+func (d *decoder) decodeMap(path string, in interface{}, v reflect.Value) {
+	t := v.Type()
+	if s, ok := indirect(in).(string); ok {
+		var m map[string]interface{}
+		if err := json.Unmarshal([]byte(s), &m); err != nil {
+			d.fail(path, castError(in, t.String(), ErrSyntax))
+			return
+		}
+		in = m
+	}
+	rv := reflect.ValueOf(indirect(in))
+	if rv.Kind() != reflect.Map {
+		d.fail(path, castError(in, t.String(), ErrUnsupported))
+		return
+	}
+
+	out := v
+	if out.IsNil() {
+		out = reflect.MakeMapWithSize(t, rv.Len())
+	}
+	iter := rv.MapRange()
+	for iter.Next() {
+		key := iter.Key().Interface()
+		child := path + "[" + fmt.Sprint(key) + "]"
+		k := reflect.New(t.Key()).Elem()
+		n := len(d.errs)
+		d.decode(child, key, k)
+		elem := reflect.New(t.Elem()).Elem()
+		d.decode(child, iter.Value().Interface(), elem)
+		if len(d.errs) == n {
+			out.SetMapIndex(k, elem)
+		}
+	}
+	v.Set(out)
+}
+
+// 基础类型的转换，结果转换为t（支持底层是基础类型的自定义类型）
+func (d *decoder) scalar(in interface{}, t reflect.Type) (reflect.Value, error) {
+	var (
+		x   interface{}
+		err error
+	)
+	switch t.Kind() {
+	case reflect.Bool:
+		x, err = d.c.ToBoolE(in)
+	case reflect.String:
+		x, err = d.c.ToStringE(in)
+	case reflect.Int:
+		x, err = d.c.ToIntE(in)
+	case reflect.Int8:
+		x, err = d.c.ToInt8E(in)
+	case reflect.Int16:
+		x, err = d.c.ToInt16E(in)
+	case reflect.Int32:
+		x, err = d.c.ToInt32E(in)
+	case reflect.Int64:
+		x, err = d.c.ToInt64E(in)
+	case reflect.Uint:
+		x, err = d.c.ToUintE(in)
+	case reflect.Uint8:
+		x, err = d.c.ToUint8E(in)
+	case reflect.Uint16:
+		x, err = d.c.ToUint16E(in)
+	case reflect.Uint32:
+		x, err = d.c.ToUint32E(in)
+	case reflect.Uint64:
+		x, err = d.c.ToUint64E(in)
+	case reflect.Float32:
+		x, err = d.c.ToFloat32E(in)
+	case reflect.Float64:
+		x, err = d.c.ToFloat64E(in)
+	default:
+		return reflect.Value{}, castError(in, t.String(), ErrUnsupported)
+	}
+	if err != nil {
+		return reflect.Value{}, err
+	}
+	return reflect.ValueOf(x).Convert(t), nil
+}
+
+// 结构体对应的输入，json解析出来的是map[string]interface{}，yaml解析出来的是map[interface{}]interface{}
+func toStringMap(in interface{}) (map[string]interface{}, bool) {
+	in = indirect(in)
+	if m, ok := in.(map[string]interface{}); ok {
+		return m, true
+	}
+	rv := reflect.ValueOf(in)
+	if rv.Kind() != reflect.Map {
+		return nil, false
+	}
+	m := make(map[string]interface{}, rv.Len())
+	iter := rv.MapRange()
+	for iter.Next() {
+		m[fmt.Sprint(iter.Key().Interface())] = iter.Value().Interface()
+	}
+	return m, true
+}
+
+func lookupFold(m map[string]interface{}, name string) (interface{}, bool) {
+	if v, ok := m[name]; ok {
+		return v, true
+	}
+	for k, v := range m {
+		if strings.EqualFold(k, name) {
+			return v, true
+		}
+	}
+	return nil, false
+}
+
+// 解析字符串形式的列表，以 [ 开头的按JSON数组解析，否则按逗号分隔，每一项去掉首尾空白
+func splitList(s string) ([]interface{}, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return []interface{}{}, nil
+	}
+	if strings.HasPrefix(s, "[") {
+		var items []interface{}
+		if err := json.Unmarshal([]byte(s), &items); err != nil {
+			return nil, ErrSyntax
+		}
+		return items, nil
+	}
+	parts := strings.Split(s, ",")
+	items := make([]interface{}, len(parts))
+	for i, p := range parts {
+		items[i] = strings.TrimSpace(p)
+	}
+	return items, nil
+}
@@ -0,0 +1,111 @@
+package cast
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+type server struct {
+	Host string `cast:"host"`
+	Port int    `cast:"port"`
+}
+
+type base struct {
+	Name string `cast:"name"`
+}
+
+type config struct {
+	base
+	Servers  []server          `cast:"servers"`
+	Primary  *server           `cast:"primary"`
+	Labels   map[string]int    `cast:"labels"`
+	Timeout  time.Duration     `cast:"timeout"`
+	Start    time.Time         `cast:"start"`
+	Debug    bool              `cast:"debug"`
+	Ratio    float64           // 没有标签，按字段名匹配
+	Ignored  string            `cast:"-"`
+	Extra    interface{}       `cast:"extra"`
+	Level    level             `cast:"level"`
+	Defaults map[string]server `cast:"defaults"`
+}
+
+type level int8
+
+func TestDecode(t *testing.T) {
+	var input map[string]interface{}
+	data := `{
+		"name": "demo",
+		"servers": [{"host": "a", "port": 80}, {"host": "b", "port": "8080"}],
+		"primary": {"host": "p", "port": 1},
+		"labels": {"x": "1", "y": 2},
+		"timeout": "1.5s",
+		"start": "2022-01-02T03:04:05Z",
+		"debug": "true",
+		"ratio": 0.5,
+		"Ignored": "no",
+		"extra": [1, 2],
+		"level": 3,
+		"defaults": {"d": {"host": "d", "port": 9}}
+	}`
+	if err := json.Unmarshal([]byte(data), &input); err != nil {
+		t.Fatal(err)
+	}
+	var cfg config
+	if err := Decode(input, &cfg, Strict); err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Name != "demo" || len(cfg.Servers) != 2 || cfg.Servers[1].Port != 8080 ||
+		cfg.Primary == nil || cfg.Primary.Host != "p" || cfg.Labels["x"] != 1 || cfg.Labels["y"] != 2 ||
+		cfg.Timeout != 1500*time.Millisecond || cfg.Start.Year() != 2022 || !cfg.Debug ||
+		cfg.Ratio != 0.5 || cfg.Ignored != "" || cfg.Extra == nil || cfg.Level != 3 || cfg.Defaults["d"].Port != 9 {
+		t.Errorf("cfg:%+v", cfg)
+	}
+}
+
+func TestDecodeErrors(t *testing.T) {
+	input := map[string]interface{}{
+		"servers": []interface{}{
+			map[string]interface{}{"port": 80},
+			map[string]interface{}{"port": 8.5},
+			map[string]interface{}{"port": "abc"},
+		},
+		"labels":  map[string]interface{}{"x": "y"},
+		"timeout": "soon",
+		"level":   1000,
+	}
+	var cfg config
+	err := Decode(input, &cfg, Strict)
+	var de *DecodeError
+	if !errors.As(err, &de) {
+		t.Fatalf("err:%v", err)
+	}
+	paths := map[string]error{}
+	for _, fe := range de.Errors {
+		paths[fe.Path] = fe.Err
+	}
+	want := map[string]error{
+		"servers[1].port": ErrLossy,
+		"servers[2].port": ErrSyntax,
+		"labels[x]":       ErrSyntax,
+		"timeout":         ErrSyntax,
+		"level":           ErrOverflow,
+	}
+	if len(paths) != len(want) {
+		t.Errorf("err:%v", err)
+	}
+	for path, e := range want {
+		if !errors.Is(paths[path], e) {
+			t.Errorf("path:%v, err:%v", path, paths[path])
+		}
+	}
+	// 其他字段照常解码
+	if len(cfg.Servers) != 3 || cfg.Servers[0].Port != 80 {
+		t.Errorf("servers:%+v", cfg.Servers)
+	}
+
+	if err := Decode(input, cfg); err != ErrDecodeTarget {
+		t.Errorf("err:%v", err)
+	}
+}
@@ -0,0 +1,143 @@
+package cast
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// ConvertFunc 自定义的转换函数，把v转换为注册时的目标类型
+// 返回值的类型必须是目标类型，或者可以直接 Convert 为目标类型
+type ConvertFunc func(v interface{}) (interface{}, error)
+
+type convertKey struct {
+	from reflect.Type // nil 表示任意的源类型
+	to   reflect.Type
+}
+
+type registry struct {
+	mu    sync.RWMutex
+	funcs map[convertKey]ConvertFunc
+}
+
+func newRegistry() *registry {
+	return &registry{funcs: make(map[convertKey]ConvertFunc)}
+}
+
+func (r *registry) register(from, to reflect.Type, fn ConvertFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.funcs[convertKey{from: from, to: to}] = fn
+}
+
+// 优先使用指定了源类型的转换函数
+func (r *registry) lookup(from, to reflect.Type) ConvertFunc {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	if len(r.funcs) == 0 {
+		return nil
+	}
+	if from != nil {
+		if fn, ok := r.funcs[convertKey{from: from, to: to}]; ok {
+			return fn
+		}
+	}
+	return r.funcs[convertKey{to: to}]
+}
+
+// 全局的注册表，所有转换器都会使用，转换器自己注册的优先
+var globalRegistry = newRegistry()
+
+// Register 全局注册转换为to类型的函数，对所有转换器生效，也可以覆盖内置的转换，比如：
+//
+//	cast.Register(reflect.TypeOf(true), func(v interface{}) (interface{}, error) {
+//		switch v {
+//		case "yes":
+//			return true, nil
+//		case "no":
+//			return false, nil
+//		}
+//		return cast.Builtin(cast.Strict).ToBoolE(v)
+//	})
+//
+// 注意：转换函数中如果要回退到内置的转换，必须通过 Builtin 得到的转换器，
+// 直接调用 cast.ToBoolE 等函数会再次进入同一个转换函数，导致无限递归
+func Register(to reflect.Type, fn ConvertFunc) {
+	globalRegistry.register(nil, to, fn)
+}
+
+// RegisterFrom 全局注册从from类型转换为to类型的函数，优先于 Register 注册的函数
+// 可以用来把自定义类型转换为内置类型，比如 Money 转 string
+func RegisterFrom(from, to reflect.Type, fn ConvertFunc) {
+	globalRegistry.register(from, to, fn)
+}
+
+// Register 只对当前转换器（以及由它 WithMode 得到的转换器）生效，优先于全局注册的函数
+// 转换函数中回退到内置的转换，使用 c.Builtin()
+func (c *Converter) Register(to reflect.Type, fn ConvertFunc) {
+	c.registry.register(nil, to, fn)
+}
+
+func (c *Converter) RegisterFrom(from, to reflect.Type, fn ConvertFunc) {
+	c.registry.register(from, to, fn)
+}
+
+// 查找并执行自定义的转换，ok表示是否找到了转换函数
+func (c *Converter) custom(i interface{}, to reflect.Type) (v interface{}, ok bool, err error) {
+	if c.builtin {
+		return nil, false, nil
+	}
+	from := reflect.TypeOf(i)
+	fn := c.registry.lookup(from, to)
+	if fn == nil {
+		fn = globalRegistry.lookup(from, to)
+	}
+	if fn == nil {
+		return nil, false, nil
+	}
+
+	r, err := fn(i)
+	if err != nil {
+		return nil, true, castError(i, to.String(), err)
+	}
+	rv := reflect.ValueOf(r)
+	switch {
+	case !rv.IsValid():
+		return reflect.Zero(to).Interface(), true, nil
+	case rv.Type() == to:
+		return r, true, nil
+	case rv.Type().ConvertibleTo(to):
+		return rv.Convert(to).Interface(), true, nil
+	}
+	return nil, true, castError(i, to.String(), fmt.Errorf("%w: converter returned %T", ErrUnsupported, r))
+}
+
+// ToE 转换为任意类型t，依次尝试自定义的转换函数和内置的转换，
+// 内置的转换与 Decode 一致，支持基础类型、time.Duration、time.Time、结构体、slice、map和指针
+func (c *Converter) ToE(i interface{}, t reflect.Type) (interface{}, error) {
+	v := reflect.New(t).Elem()
+	d := &decoder{c: c}
+	d.decode("", i, v)
+	switch len(d.errs) {
+	case 0:
+		return v.Interface(), nil
+	case 1:
+		if d.errs[0].Path == "" {
+			return v.Interface(), d.errs[0].Err
+		}
+	}
+	return v.Interface(), &DecodeError{Errors: d.errs}
+}
+
+func (c *Converter) To(i interface{}, t reflect.Type) interface{} {
+	v, _ := c.ToE(i, t)
+	return v
+}
+
+func To(i interface{}, t reflect.Type, mode ...Mode) interface{} {
+	return converterOf(mode).To(i, t)
+}
+
+func ToE(i interface{}, t reflect.Type, mode ...Mode) (interface{}, error) {
+	return converterOf(mode).ToE(i, t)
+}
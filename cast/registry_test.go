@@ -0,0 +1,168 @@
+package cast
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+	"testing"
+)
+
+// 以分为单位的金额
+type money int64
+
+func parseMoney(v interface{}) (interface{}, error) {
+	s, err := ToStringE(v, Strict)
+	if err != nil {
+		return nil, err
+	}
+	f, err := strconv.ParseFloat(strings.TrimPrefix(s, "$"), 64)
+	if err != nil {
+		return nil, ErrSyntax
+	}
+	return money(f*100 + 0.5), nil
+}
+
+func TestRegistry(t *testing.T) {
+	moneyType := reflect.TypeOf(money(0))
+	c := NewConverter(WithMode(Strict))
+	c.Register(moneyType, parseMoney)
+	c.RegisterFrom(moneyType, typeString, func(v interface{}) (interface{}, error) {
+		m := v.(money)
+		return fmt.Sprintf("$%d.%02d", m/100, m%100), nil
+	})
+
+	v, err := c.ToE("$12.34", moneyType)
+	if err != nil || v.(money) != 1234 {
+		t.Errorf("v:%v, err:%v", v, err)
+	}
+	if s := c.ToString(money(1234)); s != "$12.34" {
+		t.Errorf("s:%v", s)
+	}
+	// 其他类型转string不受影响
+	if s := c.ToString(12); s != "12" {
+		t.Errorf("s:%v", s)
+	}
+	if _, err := c.ToE("abc", moneyType); !errors.Is(err, ErrSyntax) {
+		t.Errorf("err:%v", err)
+	}
+
+	// 只对当前转换器以及WithMode得到的转换器生效
+	if v := c.WithMode(Lenient).To("$1", moneyType); v.(money) != 100 {
+		t.Errorf("v:%v", v)
+	}
+	if _, err := NewConverter().ToE("$1", moneyType); err == nil {
+		t.Errorf("want error")
+	}
+
+	// Decode中的字段也会使用自定义转换
+	var out struct {
+		Price *money  `cast:"price"`
+		Items []money `cast:"items"`
+	}
+	if err := c.Decode(map[string]interface{}{"price": "$2", "items": "abc"}, &out); err == nil {
+		t.Errorf("want error")
+	}
+	if err := c.Decode(map[string]interface{}{"price": "$2", "items": []string{"$3", "4"}}, &out); err != nil {
+		t.Fatal(err)
+	}
+	if *out.Price != 200 || len(out.Items) != 2 || out.Items[1] != 400 {
+		t.Errorf("out:%+v", out)
+	}
+}
+
+func TestRegistryOverride(t *testing.T) {
+	c := NewConverter(WithMode(Strict))
+	c.Register(typeBool, func(v interface{}) (interface{}, error) {
+		switch v {
+		case "yes":
+			return true, nil
+		case "no":
+			return false, nil
+		}
+		return ToBoolE(v, Strict)
+	})
+	if v, err := c.ToBoolE("yes"); !v || err != nil {
+		t.Errorf("v:%v, err:%v", v, err)
+	}
+	if v, err := c.ToBoolE("true"); !v || err != nil {
+		t.Errorf("v:%v, err:%v", v, err)
+	}
+	if _, err := c.ToBoolE("maybe"); !errors.Is(err, ErrSyntax) {
+		t.Errorf("err:%v", err)
+	}
+	// 默认转换器不受影响
+	if _, err := ToBoolE("yes", Strict); err == nil {
+		t.Errorf("want error")
+	}
+
+	if v, err := ToE("8", reflect.TypeOf(uint16(0)), Strict); err != nil || v.(uint16) != 8 {
+		t.Errorf("v:%v, err:%v", v, err)
+	}
+}
+
+func TestRegistryInterfaceTarget(t *testing.T) {
+	errType := reflect.TypeOf((*error)(nil)).Elem()
+	c := NewConverter()
+	c.Register(errType, func(v interface{}) (interface{}, error) {
+		if s, _ := v.(string); s != "" {
+			return errors.New(s), nil
+		}
+		return nil, nil
+	})
+
+	if v, err := c.ToE("", errType); v != nil || err != nil {
+		t.Errorf("v:%v, err:%v", v, err)
+	}
+	if v, err := c.ToE("boom", errType); err != nil || v.(error).Error() != "boom" {
+		t.Errorf("v:%v, err:%v", v, err)
+	}
+
+	var out struct {
+		Err error `cast:"err"`
+	}
+	out.Err = errors.New("old")
+	if err := c.Decode(map[string]interface{}{"err": ""}, &out); err != nil || out.Err != nil {
+		t.Errorf("out:%+v, err:%v", out, err)
+	}
+}
+
+func TestRegisterGlobalBuiltin(t *testing.T) {
+	// 文档中的例子：全局覆盖bool的转换，回退到内置的转换
+	Register(typeBool, func(v interface{}) (interface{}, error) {
+		switch v {
+		case "yes":
+			return true, nil
+		case "no":
+			return false, nil
+		}
+		return Builtin(Strict).ToBoolE(v)
+	})
+	defer func() {
+		globalRegistry.mu.Lock()
+		delete(globalRegistry.funcs, convertKey{to: typeBool})
+		globalRegistry.mu.Unlock()
+	}()
+
+	for _, mode := range []Mode{Lenient, Strict} {
+		if v, err := ToBoolE("yes", mode); !v || err != nil {
+			t.Errorf("mode:%v, v:%v, err:%v", mode, v, err)
+		}
+		if v, err := ToBoolE("true", mode); !v || err != nil {
+			t.Errorf("mode:%v, v:%v, err:%v", mode, v, err)
+		}
+		if _, err := ToBoolE("maybe", mode); !errors.Is(err, ErrSyntax) {
+			t.Errorf("mode:%v, err:%v", mode, err)
+		}
+	}
+
+	// 转换器自己的 Builtin 保留其他配置，同样不走注册的函数
+	c := NewConverter(WithMode(Strict))
+	if _, err := c.Builtin().ToBoolE("yes"); err == nil {
+		t.Errorf("want error")
+	}
+	if c.Builtin().WithMode(Lenient).Mode() != Lenient {
+		t.Errorf("mode")
+	}
+}
@@ -0,0 +1,279 @@
+package cast
+
+import (
+	"encoding/json"
+	"errors"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// 严格模式的转换，源值先解引用，然后按Kind处理，所以底层是数字的自定义类型（比如 time.Duration）也能转换
+
+// 解引用指针，nil指针视为nil
+func indirect(i interface{}) interface{} {
+	if i == nil {
+		return nil
+	}
+	v := reflect.ValueOf(i)
+	for v.Kind() == reflect.Ptr {
+		if v.IsNil() {
+			return nil
+		}
+		v = v.Elem()
+	}
+	return v.Interface()
+}
+
+// 转换为bits位的有符号整数
+func strictInt(i interface{}, bits int) (int64, error) {
+	i = indirect(i)
+	switch s := i.(type) {
+	case nil:
+		return 0, nil
+	case bool:
+		if s {
+			return 1, nil
+		}
+		return 0, nil
+	case json.Number:
+		return parseInt(string(s), bits)
+	}
+
+	min, max := -int64(1)<<uint(bits-1), int64(1)<<uint(bits-1)-1
+	v := reflect.ValueOf(i)
+	switch v.Kind() {
+	case reflect.String:
+		return parseInt(v.String(), bits)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := v.Int()
+		if n < min || n > max {
+			return 0, ErrOverflow
+		}
+		return n, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		if u > uint64(max) {
+			return 0, ErrOverflow
+		}
+		return int64(u), nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if err := checkIntegral(f); err != nil {
+			return 0, err
+		}
+		// 2^(bits-1)能精确表示为float64，-2^(bits-1)本身是合法值
+		if f < -math.Ldexp(1, bits-1) || f >= math.Ldexp(1, bits-1) {
+			return 0, ErrOverflow
+		}
+		return int64(f), nil
+	}
+	return 0, ErrUnsupported
+}
+
+// 转换为bits位的无符号整数
+func strictUint(i interface{}, bits int) (uint64, error) {
+	i = indirect(i)
+	switch s := i.(type) {
+	case nil:
+		return 0, nil
+	case bool:
+		if s {
+			return 1, nil
+		}
+		return 0, nil
+	case json.Number:
+		return parseUint(string(s), bits)
+	}
+
+	max := uint64(1)<<uint(bits) - 1
+	if bits == 64 {
+		max = math.MaxUint64
+	}
+	v := reflect.ValueOf(i)
+	switch v.Kind() {
+	case reflect.String:
+		return parseUint(v.String(), bits)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := v.Int()
+		if n < 0 || uint64(n) > max {
+			return 0, ErrOverflow
+		}
+		return uint64(n), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		if u > max {
+			return 0, ErrOverflow
+		}
+		return u, nil
+	case reflect.Float32, reflect.Float64:
+		f := v.Float()
+		if err := checkIntegral(f); err != nil {
+			return 0, err
+		}
+		if f < 0 || f >= math.Ldexp(1, bits) {
+			return 0, ErrOverflow
+		}
+		return uint64(f), nil
+	}
+	return 0, ErrUnsupported
+}
+
+// 转换为bits位的浮点数，绝对值超过2^53（float32是2^24）的整数会丢失精度
+func strictFloat(i interface{}, bits int) (float64, error) {
+	i = indirect(i)
+	switch s := i.(type) {
+	case nil:
+		return 0, nil
+	case bool:
+		if s {
+			return 1, nil
+		}
+		return 0, nil
+	case json.Number:
+		return parseFloat(string(s), bits)
+	}
+
+	maxExact := maxExactInt(bits)
+	var f float64
+	v := reflect.ValueOf(i)
+	switch v.Kind() {
+	case reflect.String:
+		return parseFloat(v.String(), bits)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n := v.Int()
+		if n > maxExact || n < -maxExact {
+			return 0, ErrLossy
+		}
+		f = float64(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr:
+		u := v.Uint()
+		if u > uint64(maxExact) {
+			return 0, ErrLossy
+		}
+		f = float64(u)
+	case reflect.Float32, reflect.Float64:
+		f = v.Float()
+	default:
+		return 0, ErrUnsupported
+	}
+	if bits == 32 && !math.IsInf(f, 0) && math.Abs(f) > math.MaxFloat32 {
+		return 0, ErrOverflow
+	}
+	return f, nil
+}
+
+// 只接受true/false（以及strconv.ParseBool支持的写法），数字只接受0和1
+func strictBool(i interface{}) (bool, error) {
+	i = indirect(i)
+	switch s := i.(type) {
+	case nil:
+		return false, nil
+	case json.Number:
+		return numberToBool(string(s))
+	}
+
+	v := reflect.ValueOf(i)
+	switch v.Kind() {
+	case reflect.Bool:
+		return v.Bool(), nil
+	case reflect.String:
+		b, err := strconv.ParseBool(v.String())
+		if err != nil {
+			return false, ErrSyntax
+		}
+		return b, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64, reflect.Uintptr,
+		reflect.Float32, reflect.Float64:
+		n, err := strictInt(i, 64)
+		if err != nil || (n != 0 && n != 1) {
+			return false, ErrLossy
+		}
+		return n == 1, nil
+	}
+	return false, ErrUnsupported
+}
+
+// 字符串必须带单位（比如 "1.5s"），整数视为纳秒
+func strictDuration(i interface{}) (time.Duration, error) {
+	i = indirect(i)
+	if s, ok := i.(string); ok {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return 0, ErrSyntax
+		}
+		return d, nil
+	}
+	n, err := strictInt(i, 64)
+	return time.Duration(n), err
+}
+
+func parseInt(s string, bits int) (int64, error) {
+	n, err := strconv.ParseInt(s, 0, bits)
+	return n, numError(err)
+}
+
+func parseUint(s string, bits int) (uint64, error) {
+	n, err := strconv.ParseUint(s, 0, bits)
+	return n, numError(err)
+}
+
+// 整数写法的字符串和整数一样，超出能精确表示的范围时返回 ErrLossy；小数本身就是近似值，按bits位舍入
+func parseFloat(s string, bits int) (float64, error) {
+	f, err := strconv.ParseFloat(s, bits)
+	if err != nil {
+		return 0, numError(err)
+	}
+	maxExact := maxExactInt(bits)
+	if n, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if n > maxExact || n < -maxExact {
+			return 0, ErrLossy
+		}
+	} else if errors.Is(err, strconv.ErrRange) { // 超出int64的整数
+		return 0, ErrLossy
+	}
+	return f, nil
+}
+
+// bits位的浮点数能精确表示的最大整数
+func maxExactInt(bits int) int64 {
+	if bits == 32 {
+		return 1 << 24
+	}
+	return 1 << 53
+}
+
+func numberToBool(s string) (bool, error) {
+	switch strings.TrimSpace(s) {
+	case "0":
+		return false, nil
+	case "1":
+		return true, nil
+	}
+	return false, ErrLossy
+}
+
+// 将strconv的错误转换为本包的错误
+func numError(err error) error {
+	if err == nil {
+		return nil
+	}
+	if errors.Is(err, strconv.ErrRange) {
+		return ErrOverflow
+	}
+	return ErrSyntax
+}
+
+// 小数转整数必须没有小数部分
+func checkIntegral(f float64) error {
+	if math.IsInf(f, 0) {
+		return ErrOverflow
+	}
+	if math.IsNaN(f) || f != math.Trunc(f) {
+		return ErrLossy
+	}
+	return nil
+}
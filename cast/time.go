@@ -0,0 +1,112 @@
+package cast
+
+import (
+	"reflect"
+	"strings"
+	"time"
+)
+
+// DefaultTimeLayouts 默认按顺序尝试的时间格式
+var DefaultTimeLayouts = []string{
+	time.RFC3339Nano,
+	time.RFC3339,
+	"2006-01-02 15:04:05.999999999",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05",
+	"2006-01-02",
+	time.RFC1123Z,
+	time.RFC1123,
+	time.RFC822Z,
+	time.RFC822,
+	time.RubyDate,
+	time.UnixDate,
+	time.ANSIC,
+}
+
+// 小于这个值的整数视为Unix秒，否则视为Unix毫秒（1e11秒大约是5138年）
+const unixMilliThreshold = 1e11
+
+var typeTime = reflect.TypeOf(time.Time{})
+
+// ToTimeE 使用转换器的时区（默认UTC，不会使用机器本地的时区）和时间格式转换，
+// 支持time.Time、字符串、以及Unix时间戳（按数量级区分秒和毫秒）
+func (c *Converter) ToTimeE(i interface{}) (time.Time, error) {
+	return c.ToTimeInE(i, c.opts.Location)
+}
+
+func (c *Converter) ToTime(i interface{}) time.Time {
+	v, _ := c.ToTimeE(i)
+	return v
+}
+
+// ToTimeInE 在指定的时区loc中解析，layouts 为空时使用转换器的时间格式
+// 字符串中带有时区信息时，保留字符串中的时区偏移；time.Time 原样返回
+func (c *Converter) ToTimeInE(i interface{}, loc *time.Location, layouts ...string) (time.Time, error) {
+	if r, ok, err := c.custom(i, typeTime); ok {
+		if err != nil {
+			return time.Time{}, err
+		}
+		return r.(time.Time), nil
+	}
+	if loc == nil {
+		loc = time.UTC
+	}
+	if len(layouts) == 0 {
+		layouts = c.opts.TimeLayouts
+	}
+
+	switch s := indirect(i).(type) {
+	case nil:
+		return time.Time{}, nil
+	case time.Time:
+		return s, nil
+	case string:
+		s = strings.TrimSpace(s)
+		for _, layout := range layouts {
+			if t, err := time.ParseInLocation(layout, s, loc); err == nil {
+				return t, nil
+			}
+		}
+		// 纯数字的字符串按时间戳处理
+		if n, err := parseInt(s, 64); err == nil {
+			return unixTime(n, loc), nil
+		}
+		return time.Time{}, castError(i, "time.Time", ErrSyntax)
+	case bool:
+		return time.Time{}, castError(i, "time.Time", ErrUnsupported)
+	}
+	n, err := c.ToInt64E(i)
+	if err != nil {
+		return time.Time{}, castError(i, "time.Time", err)
+	}
+	return unixTime(n, loc), nil
+}
+
+func (c *Converter) ToTimeIn(i interface{}, loc *time.Location, layouts ...string) time.Time {
+	v, _ := c.ToTimeInE(i, loc, layouts...)
+	return v
+}
+
+func unixTime(n int64, loc *time.Location) time.Time {
+	if n < unixMilliThreshold && n > -unixMilliThreshold {
+		return time.Unix(n, 0).In(loc)
+	}
+	return time.Unix(n/1e3, n%1e3*1e6).In(loc)
+}
+
+func ToTime(i interface{}, mode ...Mode) time.Time {
+	return converterOf(mode).ToTime(i)
+}
+
+func ToTimeE(i interface{}, mode ...Mode) (time.Time, error) {
+	return converterOf(mode).ToTimeE(i)
+}
+
+// ToTimeIn 包级别的函数使用宽松模式，需要严格模式时请使用 Converter
+func ToTimeIn(i interface{}, loc *time.Location, layouts ...string) time.Time {
+	return lenientConverter.ToTimeIn(i, loc, layouts...)
+}
+
+func ToTimeInE(i interface{}, loc *time.Location, layouts ...string) (time.Time, error) {
+	return lenientConverter.ToTimeInE(i, loc, layouts...)
+}
@@ -0,0 +1,96 @@
+package cast
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestToTime(t *testing.T) {
+	shanghai := time.FixedZone("CST", 8*3600)
+	want := time.Date(2022, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	cases := []struct {
+		in   interface{}
+		want time.Time
+	}{
+		{"2022-01-02T03:04:05Z", want},
+		{"2022-01-02 03:04:05", want},
+		{"2022-01-02T11:04:05+08:00", want},
+		{want.Unix(), want},
+		{want.Unix() * 1000, want},
+		{"1641092645", want},
+		{float64(want.Unix()), want},
+	}
+	for _, c := range cases {
+		tm, err := ToTimeE(c.in, Strict)
+		if err != nil || !tm.Equal(c.want) {
+			t.Errorf("in:%v, tm:%v, err:%v", c.in, tm, err)
+		}
+		// 默认UTC，不使用机器的本地时区
+		if _, ok := c.in.(string); !ok && tm.Location() != time.UTC {
+			t.Errorf("in:%v, loc:%v", c.in, tm.Location())
+		}
+	}
+
+	// 指定时区和格式
+	tm, err := ToTimeInE("2022/01/02 11:04", shanghai, "2006/01/02 15:04")
+	if err != nil || !tm.Equal(want.Add(-5*time.Second)) {
+		t.Errorf("tm:%v, err:%v", tm, err)
+	}
+	c := NewConverter(WithLocation(shanghai), WithTimeLayouts("02/01/2006"))
+	if tm := c.ToTime("02/01/2022"); !tm.Equal(time.Date(2022, 1, 2, 0, 0, 0, 0, shanghai)) {
+		t.Errorf("tm:%v", tm)
+	}
+	if tm := c.ToTime(want.Unix()); tm.Location() != shanghai || !tm.Equal(want) {
+		t.Errorf("tm:%v", tm)
+	}
+
+	if _, err := ToTimeE("yesterday", Strict); !errors.Is(err, ErrSyntax) {
+		t.Errorf("err:%v", err)
+	}
+	if _, err := ToTimeE(1.5, Strict); !errors.Is(err, ErrLossy) {
+		t.Errorf("err:%v", err)
+	}
+}
+
+func TestToSliceOf(t *testing.T) {
+	intType := reflect.TypeOf(0)
+	for _, in := range []interface{}{"1, 2,3", "[1, 2, 3]", []string{"1", "2", "3"}, [3]float64{1, 2, 3}} {
+		v, err := ToSliceOfE(in, intType, Strict)
+		if err != nil || !reflect.DeepEqual(v, []int{1, 2, 3}) {
+			t.Errorf("in:%v, v:%v, err:%v", in, v, err)
+		}
+	}
+	if v := ToSliceOf("", intType); len(v.([]int)) != 0 {
+		t.Errorf("v:%v", v)
+	}
+	if _, err := ToSliceOfE("1, x", intType, Strict); err == nil {
+		t.Errorf("want error")
+	}
+	if _, err := ToSliceOfE("[1, ", intType, Strict); !errors.Is(err, ErrSyntax) {
+		t.Errorf("err:%v", err)
+	}
+
+	ds, err := ToSliceOfE("1s,2m", reflect.TypeOf(time.Duration(0)), Strict)
+	if err != nil || !reflect.DeepEqual(ds, []time.Duration{time.Second, 2 * time.Minute}) {
+		t.Errorf("ds:%v, err:%v", ds, err)
+	}
+}
+
+func TestToStringMapOf(t *testing.T) {
+	intType := reflect.TypeOf(0)
+	for _, in := range []interface{}{
+		`{"a": 1, "b": "2"}`,
+		map[interface{}]interface{}{"a": 1, "b": "2"},
+	} {
+		v, err := ToStringMapOfE(in, intType, Strict)
+		if err != nil || !reflect.DeepEqual(v, map[string]int{"a": 1, "b": 2}) {
+			t.Errorf("in:%v, v:%v, err:%v", in, v, err)
+		}
+	}
+	if _, err := ToStringMapOfE(`{"a": 1.5}`, intType, Strict); !errors.Is(err, ErrLossy) {
+		t.Errorf("err:%v", err)
+	}
+}
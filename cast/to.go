@@ -0,0 +1,491 @@
+package cast
+
+import (
+	"reflect"
+	"strconv"
+	"time"
+
+	spfcast "github.com/spf13/cast"
+)
+
+var (
+	typeInt      = reflect.TypeOf(int(0))
+	typeInt8     = reflect.TypeOf(int8(0))
+	typeInt16    = reflect.TypeOf(int16(0))
+	typeInt32    = reflect.TypeOf(int32(0))
+	typeInt64    = reflect.TypeOf(int64(0))
+	typeUint     = reflect.TypeOf(uint(0))
+	typeUint8    = reflect.TypeOf(uint8(0))
+	typeUint16   = reflect.TypeOf(uint16(0))
+	typeUint32   = reflect.TypeOf(uint32(0))
+	typeUint64   = reflect.TypeOf(uint64(0))
+	typeFloat32  = reflect.TypeOf(float32(0))
+	typeFloat64  = reflect.TypeOf(float64(0))
+	typeBool     = reflect.TypeOf(false)
+	typeString   = reflect.TypeOf("")
+	typeDuration = reflect.TypeOf(time.Duration(0))
+)
+
+// 每个目标类型都有 ToXXX 和 ToXXXE 两个方法，ToXXX 在转换失败时返回零值，ToXXXE 额外返回错误
+// 宽松模式直接使用spf13/cast的实现，严格模式见 strict.go，注册了自定义转换函数时优先使用自定义的
+
+func (c *Converter) ToInt(i interface{}) int {
+	v, _ := c.ToIntE(i)
+	return v
+}
+
+func (c *Converter) ToIntE(i interface{}) (int, error) {
+	if r, ok, err := c.custom(i, typeInt); ok {
+		if err != nil {
+			return 0, err
+		}
+		return r.(int), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToIntE(i)
+		return v, castError(i, "int", err)
+	}
+	v, err := strictInt(i, strconv.IntSize)
+	if err != nil {
+		return 0, castError(i, "int", err)
+	}
+	return int(v), nil
+}
+
+func (c *Converter) ToInt8(i interface{}) int8 {
+	v, _ := c.ToInt8E(i)
+	return v
+}
+
+func (c *Converter) ToInt8E(i interface{}) (int8, error) {
+	if r, ok, err := c.custom(i, typeInt8); ok {
+		if err != nil {
+			return 0, err
+		}
+		return r.(int8), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToInt8E(i)
+		return v, castError(i, "int8", err)
+	}
+	v, err := strictInt(i, 8)
+	if err != nil {
+		return 0, castError(i, "int8", err)
+	}
+	return int8(v), nil
+}
+
+func (c *Converter) ToInt16(i interface{}) int16 {
+	v, _ := c.ToInt16E(i)
+	return v
+}
+
+func (c *Converter) ToInt16E(i interface{}) (int16, error) {
+	if r, ok, err := c.custom(i, typeInt16); ok {
+		if err != nil {
+			return 0, err
+		}
+		return r.(int16), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToInt16E(i)
+		return v, castError(i, "int16", err)
+	}
+	v, err := strictInt(i, 16)
+	if err != nil {
+		return 0, castError(i, "int16", err)
+	}
+	return int16(v), nil
+}
+
+func (c *Converter) ToInt32(i interface{}) int32 {
+	v, _ := c.ToInt32E(i)
+	return v
+}
+
+func (c *Converter) ToInt32E(i interface{}) (int32, error) {
+	if r, ok, err := c.custom(i, typeInt32); ok {
+		if err != nil {
+			return 0, err
+		}
+		return r.(int32), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToInt32E(i)
+		return v, castError(i, "int32", err)
+	}
+	v, err := strictInt(i, 32)
+	if err != nil {
+		return 0, castError(i, "int32", err)
+	}
+	return int32(v), nil
+}
+
+func (c *Converter) ToInt64(i interface{}) int64 {
+	v, _ := c.ToInt64E(i)
+	return v
+}
+
+func (c *Converter) ToInt64E(i interface{}) (int64, error) {
+	if r, ok, err := c.custom(i, typeInt64); ok {
+		if err != nil {
+			return 0, err
+		}
+		return r.(int64), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToInt64E(i)
+		return v, castError(i, "int64", err)
+	}
+	v, err := strictInt(i, 64)
+	if err != nil {
+		return 0, castError(i, "int64", err)
+	}
+	return int64(v), nil
+}
+
+func (c *Converter) ToUint(i interface{}) uint {
+	v, _ := c.ToUintE(i)
+	return v
+}
+
+func (c *Converter) ToUintE(i interface{}) (uint, error) {
+	if r, ok, err := c.custom(i, typeUint); ok {
+		if err != nil {
+			return 0, err
+		}
+		return r.(uint), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToUintE(i)
+		return v, castError(i, "uint", err)
+	}
+	v, err := strictUint(i, strconv.IntSize)
+	if err != nil {
+		return 0, castError(i, "uint", err)
+	}
+	return uint(v), nil
+}
+
+func (c *Converter) ToUint8(i interface{}) uint8 {
+	v, _ := c.ToUint8E(i)
+	return v
+}
+
+func (c *Converter) ToUint8E(i interface{}) (uint8, error) {
+	if r, ok, err := c.custom(i, typeUint8); ok {
+		if err != nil {
+			return 0, err
+		}
+		return r.(uint8), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToUint8E(i)
+		return v, castError(i, "uint8", err)
+	}
+	v, err := strictUint(i, 8)
+	if err != nil {
+		return 0, castError(i, "uint8", err)
+	}
+	return uint8(v), nil
+}
+
+func (c *Converter) ToUint16(i interface{}) uint16 {
+	v, _ := c.ToUint16E(i)
+	return v
+}
+
+func (c *Converter) ToUint16E(i interface{}) (uint16, error) {
+	if r, ok, err := c.custom(i, typeUint16); ok {
+		if err != nil {
+			return 0, err
+		}
+		return r.(uint16), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToUint16E(i)
+		return v, castError(i, "uint16", err)
+	}
+	v, err := strictUint(i, 16)
+	if err != nil {
+		return 0, castError(i, "uint16", err)
+	}
+	return uint16(v), nil
+}
+
+func (c *Converter) ToUint32(i interface{}) uint32 {
+	v, _ := c.ToUint32E(i)
+	return v
+}
+
+func (c *Converter) ToUint32E(i interface{}) (uint32, error) {
+	if r, ok, err := c.custom(i, typeUint32); ok {
+		if err != nil {
+			return 0, err
+		}
+		return r.(uint32), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToUint32E(i)
+		return v, castError(i, "uint32", err)
+	}
+	v, err := strictUint(i, 32)
+	if err != nil {
+		return 0, castError(i, "uint32", err)
+	}
+	return uint32(v), nil
+}
+
+func (c *Converter) ToUint64(i interface{}) uint64 {
+	v, _ := c.ToUint64E(i)
+	return v
+}
+
+func (c *Converter) ToUint64E(i interface{}) (uint64, error) {
+	if r, ok, err := c.custom(i, typeUint64); ok {
+		if err != nil {
+			return 0, err
+		}
+		return r.(uint64), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToUint64E(i)
+		return v, castError(i, "uint64", err)
+	}
+	v, err := strictUint(i, 64)
+	if err != nil {
+		return 0, castError(i, "uint64", err)
+	}
+	return uint64(v), nil
+}
+
+func (c *Converter) ToFloat32(i interface{}) float32 {
+	v, _ := c.ToFloat32E(i)
+	return v
+}
+
+func (c *Converter) ToFloat32E(i interface{}) (float32, error) {
+	if r, ok, err := c.custom(i, typeFloat32); ok {
+		if err != nil {
+			return 0, err
+		}
+		return r.(float32), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToFloat32E(i)
+		return v, castError(i, "float32", err)
+	}
+	v, err := strictFloat(i, 32)
+	if err != nil {
+		return 0, castError(i, "float32", err)
+	}
+	return float32(v), nil
+}
+
+func (c *Converter) ToFloat64(i interface{}) float64 {
+	v, _ := c.ToFloat64E(i)
+	return v
+}
+
+func (c *Converter) ToFloat64E(i interface{}) (float64, error) {
+	if r, ok, err := c.custom(i, typeFloat64); ok {
+		if err != nil {
+			return 0, err
+		}
+		return r.(float64), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToFloat64E(i)
+		return v, castError(i, "float64", err)
+	}
+	v, err := strictFloat(i, 64)
+	if err != nil {
+		return 0, castError(i, "float64", err)
+	}
+	return float64(v), nil
+}
+
+func (c *Converter) ToBool(i interface{}) bool {
+	v, _ := c.ToBoolE(i)
+	return v
+}
+
+func (c *Converter) ToBoolE(i interface{}) (bool, error) {
+	if r, ok, err := c.custom(i, typeBool); ok {
+		if err != nil {
+			return false, err
+		}
+		return r.(bool), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToBoolE(i)
+		return v, castError(i, "bool", err)
+	}
+	v, err := strictBool(i)
+	if err != nil {
+		return false, castError(i, "bool", err)
+	}
+	return v, nil
+}
+
+// 转字符串不存在有损的问题，两种模式的行为一致
+func (c *Converter) ToString(i interface{}) string {
+	v, _ := c.ToStringE(i)
+	return v
+}
+
+func (c *Converter) ToStringE(i interface{}) (string, error) {
+	if r, ok, err := c.custom(i, typeString); ok {
+		if err != nil {
+			return "", err
+		}
+		return r.(string), nil
+	}
+	v, err := spfcast.ToStringE(i)
+	return v, castError(i, "string", err)
+}
+
+func (c *Converter) ToDuration(i interface{}) time.Duration {
+	v, _ := c.ToDurationE(i)
+	return v
+}
+
+func (c *Converter) ToDurationE(i interface{}) (time.Duration, error) {
+	if r, ok, err := c.custom(i, typeDuration); ok {
+		if err != nil {
+			return 0, err
+		}
+		return r.(time.Duration), nil
+	}
+	if c.opts.Mode == Lenient {
+		v, err := spfcast.ToDurationE(i)
+		return v, castError(i, "time.Duration", err)
+	}
+	v, err := strictDuration(i)
+	if err != nil {
+		return 0, castError(i, "time.Duration", err)
+	}
+	return v, nil
+}
+
+// 包级别的函数，mode 可选，默认宽松模式
+
+func ToInt(i interface{}, mode ...Mode) int {
+	return converterOf(mode).ToInt(i)
+}
+
+func ToIntE(i interface{}, mode ...Mode) (int, error) {
+	return converterOf(mode).ToIntE(i)
+}
+
+func ToInt8(i interface{}, mode ...Mode) int8 {
+	return converterOf(mode).ToInt8(i)
+}
+
+func ToInt8E(i interface{}, mode ...Mode) (int8, error) {
+	return converterOf(mode).ToInt8E(i)
+}
+
+func ToInt16(i interface{}, mode ...Mode) int16 {
+	return converterOf(mode).ToInt16(i)
+}
+
+func ToInt16E(i interface{}, mode ...Mode) (int16, error) {
+	return converterOf(mode).ToInt16E(i)
+}
+
+func ToInt32(i interface{}, mode ...Mode) int32 {
+	return converterOf(mode).ToInt32(i)
+}
+
+func ToInt32E(i interface{}, mode ...Mode) (int32, error) {
+	return converterOf(mode).ToInt32E(i)
+}
+
+func ToInt64(i interface{}, mode ...Mode) int64 {
+	return converterOf(mode).ToInt64(i)
+}
+
+func ToInt64E(i interface{}, mode ...Mode) (int64, error) {
+	return converterOf(mode).ToInt64E(i)
+}
+
+func ToUint(i interface{}, mode ...Mode) uint {
+	return converterOf(mode).ToUint(i)
+}
+
+func ToUintE(i interface{}, mode ...Mode) (uint, error) {
+	return converterOf(mode).ToUintE(i)
+}
+
+func ToUint8(i interface{}, mode ...Mode) uint8 {
+	return converterOf(mode).ToUint8(i)
+}
+
+func ToUint8E(i interface{}, mode ...Mode) (uint8, error) {
+	return converterOf(mode).ToUint8E(i)
+}
+
+func ToUint16(i interface{}, mode ...Mode) uint16 {
+	return converterOf(mode).ToUint16(i)
+}
+
+func ToUint16E(i interface{}, mode ...Mode) (uint16, error) {
+	return converterOf(mode).ToUint16E(i)
+}
+
+func ToUint32(i interface{}, mode ...Mode) uint32 {
+	return converterOf(mode).ToUint32(i)
+}
+
+func ToUint32E(i interface{}, mode ...Mode) (uint32, error) {
+	return converterOf(mode).ToUint32E(i)
+}
+
+func ToUint64(i interface{}, mode ...Mode) uint64 {
+	return converterOf(mode).ToUint64(i)
+}
+
+func ToUint64E(i interface{}, mode ...Mode) (uint64, error) {
+	return converterOf(mode).ToUint64E(i)
+}
+
+func ToFloat32(i interface{}, mode ...Mode) float32 {
+	return converterOf(mode).ToFloat32(i)
+}
+
+func ToFloat32E(i interface{}, mode ...Mode) (float32, error) {
+	return converterOf(mode).ToFloat32E(i)
+}
+
+func ToFloat64(i interface{}, mode ...Mode) float64 {
+	return converterOf(mode).ToFloat64(i)
+}
+
+func ToFloat64E(i interface{}, mode ...Mode) (float64, error) {
+	return converterOf(mode).ToFloat64E(i)
+}
+
+func ToBool(i interface{}, mode ...Mode) bool {
+	return converterOf(mode).ToBool(i)
+}
+
+func ToBoolE(i interface{}, mode ...Mode) (bool, error) {
+	return converterOf(mode).ToBoolE(i)
+}
+
+func ToString(i interface{}, mode ...Mode) string {
+	return converterOf(mode).ToString(i)
+}
+
+func ToStringE(i interface{}, mode ...Mode) (string, error) {
+	return converterOf(mode).ToStringE(i)
+}
+
+func ToDuration(i interface{}, mode ...Mode) time.Duration {
+	return converterOf(mode).ToDuration(i)
+}
+
+func ToDurationE(i interface{}, mode ...Mode) (time.Duration, error) {
+	return converterOf(mode).ToDurationE(i)
+}
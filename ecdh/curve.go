@@ -0,0 +1,208 @@
+package ecdh
+
+import (
+	"crypto/elliptic"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+
+	"golang.org/x/crypto/curve25519"
+)
+
+// CurveID 曲线的标识，取值与TLS的NamedGroup一致（RFC 8446 4.2.7）
+type CurveID uint16
+
+const (
+	CurveP256   CurveID = 23
+	CurveP384   CurveID = 24
+	CurveP521   CurveID = 25
+	CurveX25519 CurveID = 29
+)
+
+func (id CurveID) String() string {
+	if c, ok := curves[id]; ok {
+		return c.Name()
+	}
+	return fmt.Sprintf("CurveID(%d)", uint16(id))
+}
+
+var (
+	// ErrUnsupportedCurve 不支持的曲线
+	ErrUnsupportedCurve = errors.New("ecdh: unsupported curve")
+	// ErrCurveMismatch 对端的公钥和自己的私钥不在同一条曲线上
+	ErrCurveMismatch = errors.New("ecdh: curve mismatch")
+)
+
+// Curve 秘钥交换使用的曲线，只能使用本包注册的曲线：P256、P384、P521、X25519
+type Curve interface {
+	ID() CurveID
+	Name() string
+	// 私钥、公钥（不带曲线标识）、共享秘钥的字节长度
+	keySize() int
+	generateKey(rand io.Reader) (d, pub []byte, err error)
+	// 由私钥计算公钥，用于反序列化时校验
+	publicKey(d []byte) ([]byte, error)
+	sharedSecret(d, peerPub []byte) ([]byte, error)
+}
+
+var (
+	P256   Curve = &nistCurve{id: CurveP256, curve: elliptic.P256()}
+	P384   Curve = &nistCurve{id: CurveP384, curve: elliptic.P384()}
+	P521   Curve = &nistCurve{id: CurveP521, curve: elliptic.P521()}
+	X25519 Curve = x25519Curve{}
+)
+
+// 曲线的注册表
+var curves = map[CurveID]Curve{
+	CurveP256:   P256,
+	CurveP384:   P384,
+	CurveP521:   P521,
+	CurveX25519: X25519,
+}
+
+// Curves 支持的所有曲线
+func Curves() []Curve {
+	return []Curve{P256, P384, P521, X25519}
+}
+
+// CurveByID 按标识查找曲线
+func CurveByID(id CurveID) (Curve, error) {
+	if c, ok := curves[id]; ok {
+		return c, nil
+	}
+	return nil, fmt.Errorf("%w: %v", ErrUnsupportedCurve, id)
+}
+
+// CurveByName 按名字查找曲线，名字是 P-256、P-384、P-521、X25519
+func CurveByName(name string) (Curve, error) {
+	for _, c := range curves {
+		if c.Name() == name {
+			return c, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %q", ErrUnsupportedCurve, name)
+}
+
+// 公钥的传输格式：| 曲线标识(2字节，大端) | 公钥 |
+// P-256、P-384、P-521的公钥是未压缩的点（elliptic.Marshal），X25519的公钥是32字节
+const curveIDSize = 2
+
+func encodePublicKey(c Curve, pub []byte) []byte {
+	b := make([]byte, curveIDSize+len(pub))
+	binary.BigEndian.PutUint16(b, uint16(c.ID()))
+	copy(b[curveIDSize:], pub)
+	return b
+}
+
+// 兼容 GenECDSAKey_secp256r1 产生的不带曲线标识的P-256公钥（65字节，以0x04开头）
+func decodePublicKey(b []byte) (Curve, []byte, error) {
+	if len(b) == 65 && b[0] == 4 {
+		return P256, b, nil
+	}
+	if len(b) < curveIDSize {
+		return nil, nil, fmt.Errorf("%w: too short", ErrInvalidPublicKey)
+	}
+	c, err := CurveByID(CurveID(binary.BigEndian.Uint16(b)))
+	if err != nil {
+		return nil, nil, err
+	}
+	return c, b[curveIDSize:], nil
+}
+
+// PeerCurve 对端公钥所使用的曲线，服务端可以据此在同一条曲线上生成自己的密钥对
+func PeerCurve(peerPub []byte) (Curve, error) {
+	c, _, err := decodePublicKey(peerPub)
+	return c, err
+}
+
+// CheckCompatible 检查对端的公钥和自己的私钥是否在同一条曲线上
+func CheckCompatible(priv *PrivateKey, peerPub []byte) error {
+	if priv == nil || priv.curve == nil {
+		return ErrInvalidPrivateKey
+	}
+	c, _, err := decodePublicKey(peerPub)
+	if err != nil {
+		return err
+	}
+	if c != priv.curve {
+		return fmt.Errorf("%w: local %s, peer %s", ErrCurveMismatch, priv.curve.Name(), c.Name())
+	}
+	return nil
+}
+
+// NIST曲线，基于crypto/elliptic
+type nistCurve struct {
+	id    CurveID
+	curve elliptic.Curve
+}
+
+func (c *nistCurve) ID() CurveID  { return c.id }
+func (c *nistCurve) Name() string { return c.curve.Params().Name }
+func (c *nistCurve) keySize() int { return (c.curve.Params().BitSize + 7) / 8 }
+
+func (c *nistCurve) generateKey(rand io.Reader) ([]byte, []byte, error) {
+	d, x, y, err := elliptic.GenerateKey(c.curve, rand)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, elliptic.Marshal(c.curve, x, y), nil
+}
+
+func (c *nistCurve) publicKey(d []byte) ([]byte, error) {
+	x, y := c.curve.ScalarBaseMult(d)
+	return elliptic.Marshal(c.curve, x, y), nil
+}
+
+// 结果是共享点的x坐标（RFC5903 9），固定为曲线的字节长度，不足时前面补0
+func (c *nistCurve) sharedSecret(d, peerPub []byte) ([]byte, error) {
+	x, y := elliptic.Unmarshal(c.curve, peerPub) // 会校验点是否在曲线上
+	if x == nil {
+		return nil, fmt.Errorf("%w: cannot unmarshal %d bytes on %s", ErrInvalidPublicKey, len(peerPub), c.Name())
+	}
+	sx, _ := c.curve.ScalarMult(x, y, d)
+	return leftPad(sx.Bytes(), c.keySize()), nil
+}
+
+// X25519（RFC 7748）
+type x25519Curve struct{}
+
+func (x25519Curve) ID() CurveID  { return CurveX25519 }
+func (x25519Curve) Name() string { return "X25519" }
+func (x25519Curve) keySize() int { return curve25519.ScalarSize }
+
+func (c x25519Curve) generateKey(rand io.Reader) ([]byte, []byte, error) {
+	d := make([]byte, curve25519.ScalarSize)
+	if _, err := io.ReadFull(rand, d); err != nil {
+		return nil, nil, err
+	}
+	pub, err := c.publicKey(d)
+	if err != nil {
+		return nil, nil, err
+	}
+	return d, pub, nil
+}
+
+func (x25519Curve) publicKey(d []byte) ([]byte, error) {
+	return curve25519.X25519(d, curve25519.Basepoint)
+}
+
+func (x25519Curve) sharedSecret(d, peerPub []byte) ([]byte, error) {
+	if len(peerPub) != curve25519.PointSize {
+		return nil, fmt.Errorf("%w: cannot unmarshal %d bytes on X25519", ErrInvalidPublicKey, len(peerPub))
+	}
+	secret, err := curve25519.X25519(d, peerPub)
+	if err != nil { // 低阶点，得到的共享秘钥全是0
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPublicKey, err)
+	}
+	return secret, nil
+}
+
+func leftPad(b []byte, size int) []byte {
+	if len(b) >= size {
+		return b
+	}
+	out := make([]byte, size)
+	copy(out[size-len(b):], b)
+	return out
+}
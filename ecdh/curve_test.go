@@ -0,0 +1,88 @@
+package ecdh
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestCurveNegotiation(t *testing.T) {
+	// 客户端使用X25519，服务端根据客户端的公钥选择同一条曲线
+	privC, pubC, err := GenerateKeyPair(X25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	curve, err := PeerCurve(pubC)
+	if err != nil || curve != X25519 || curve.ID() != CurveX25519 {
+		t.Fatalf("curve:%v, err:%v", curve, err)
+	}
+	privS, pubS, err := GenerateKeyPair(curve)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secretC, err := SharedSecret(privC, pubS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	secretS, err := SharedSecret(privS, pubC)
+	if err != nil || !bytes.Equal(secretC, secretS) || len(secretS) != 32 {
+		t.Errorf("secretC:%x, secretS:%x, err:%v", secretC, secretS, err)
+	}
+
+	// 曲线不一致
+	_, pub256, _ := GenerateKeyPair(P256)
+	err = CheckCompatible(privS, pub256)
+	if !errors.Is(err, ErrCurveMismatch) || !strings.Contains(err.Error(), "local X25519, peer P-256") {
+		t.Errorf("err:%v", err)
+	}
+	if _, err := SharedSecret(privS, pub256); !errors.Is(err, ErrCurveMismatch) {
+		t.Errorf("err:%v", err)
+	}
+}
+
+func TestCurveRegistry(t *testing.T) {
+	for _, c := range Curves() {
+		byID, err := CurveByID(c.ID())
+		if err != nil || byID != c {
+			t.Errorf("id:%v, err:%v", c.ID(), err)
+		}
+		byName, err := CurveByName(c.Name())
+		if err != nil || byName != c {
+			t.Errorf("name:%v, err:%v", c.Name(), err)
+		}
+	}
+	if _, err := CurveByID(99); !errors.Is(err, ErrUnsupportedCurve) {
+		t.Errorf("err:%v", err)
+	}
+	if _, err := PeerCurve([]byte{0, 99, 1, 2}); !errors.Is(err, ErrUnsupportedCurve) {
+		t.Errorf("err:%v", err)
+	}
+}
+
+func TestLegacyPublicKey(t *testing.T) {
+	// GenECDSAKey_secp256r1 产生的公钥不带曲线标识，按P-256处理
+	_, legacy, err := GenECDSAKey_secp256r1()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if c, err := PeerCurve(legacy); err != nil || c != P256 {
+		t.Errorf("curve:%v, err:%v", c, err)
+	}
+	priv, _, _ := GenerateKeyPair(P256)
+	if _, err := SharedSecret(priv, legacy); err != nil {
+		t.Error(err)
+	}
+}
+
+func TestX25519LowOrderPoint(t *testing.T) {
+	priv, _, _ := GenerateKeyPair(X25519)
+	zero := encodePublicKey(X25519, make([]byte, 32))
+	if _, err := SharedSecret(priv, zero); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Errorf("err:%v", err)
+	}
+	short := encodePublicKey(X25519, make([]byte, 31))
+	if _, err := SharedSecret(priv, short); !errors.Is(err, ErrInvalidPublicKey) {
+		t.Errorf("err:%v", err)
+	}
+}
@@ -11,6 +11,7 @@ import (
 	crand "crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"github.com/wsddn/go-ecdh"
 	"hash"
@@ -18,6 +19,7 @@ import (
 )
 
 // 基于椭圆曲线 elliptic.P256生成私钥、公钥
+// 返回的私钥是go-ecdh的非导出类型，无法持久化，新代码请使用 GenerateKeyPair(P256)
 func GenECDSAKey_secp256r1() (crypto.PrivateKey, []byte, error) {
 	curve := ecdh.NewEllipticECDH(elliptic.P256())
 
@@ -31,69 +33,133 @@ func GenECDSAKey_secp256r1() (crypto.PrivateKey, []byte, error) {
 	return privateKey, pubKeyBytes, nil
 }
 
+var (
+	// ErrInvalidCurve 曲线为空
+	ErrInvalidCurve = errors.New("ecdh: invalid curve")
+	// ErrInvalidPrivateKey 私钥为空或者不合法
+	ErrInvalidPrivateKey = errors.New("ecdh: invalid private key")
+	// ErrInvalidPublicKey 对端的公钥无法解码，或者不在曲线上（X25519是低阶点）
+	ErrInvalidPublicKey = errors.New("ecdh: invalid public key")
+	// ErrInvalidKeyLength 派生的秘钥长度不合法，HKDF最多只能派生255个哈希长度
+	ErrInvalidKeyLength = errors.New("ecdh: invalid key length")
+)
+
+// PrivateKey 秘钥交换的私钥，连同公钥一起保存
+type PrivateKey struct {
+	curve Curve
+	d     []byte
+	pub   []byte // 不带曲线标识的公钥
+}
+
+// Curve 私钥所在的曲线
+func (k *PrivateKey) Curve() Curve {
+	return k.curve
+}
+
+// PublicKey 公钥的传输格式，带有曲线标识，对端可以据此判断使用的是哪条曲线
+func (k *PrivateKey) PublicKey() []byte {
+	return encodePublicKey(k.curve, k.pub)
+}
+
+// GenerateKeyPair 在curve上生成密钥对，返回私钥和编码之后的公钥
+// 公钥之所以是[]byte，是因为公钥是需要通过网络交给对端的
+func GenerateKeyPair(curve Curve) (*PrivateKey, []byte, error) {
+	if curve == nil {
+		return nil, nil, ErrInvalidCurve
+	}
+	d, pub, err := curve.generateKey(crand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	priv := &PrivateKey{curve: curve, d: d, pub: pub}
+	return priv, priv.PublicKey(), nil
+}
+
+// SharedSecret 用自己的私钥和对端发来的公钥计算共享秘钥，双方得到的结果一致
+// 对端的公钥不在同一条曲线上时返回 ErrCurveMismatch，公钥不合法时返回 ErrInvalidPublicKey
+// 共享秘钥不应该直接当做对称秘钥使用，需要再经过 DeriveKey
+func SharedSecret(priv *PrivateKey, peerPub []byte) ([]byte, error) {
+	if priv == nil || priv.curve == nil || len(priv.d) == 0 {
+		return nil, ErrInvalidPrivateKey
+	}
+	if err := CheckCompatible(priv, peerPub); err != nil {
+		return nil, err
+	}
+	_, raw, _ := decodePublicKey(peerPub)
+	return priv.curve.sharedSecret(priv.d, raw)
+}
+
+// DeriveKey 基于 HKDF-SHA256 从共享秘钥中派生出length字节的秘钥，
+// salt和info的含义见 HKDF，通信双方必须使用相同的salt和info
+func DeriveKey(secret, salt, info []byte, length int) ([]byte, error) {
+	if len(secret) == 0 {
+		return nil, fmt.Errorf("%w: empty secret", ErrInvalidKeyLength)
+	}
+	if length <= 0 || length > 255*sha256.Size {
+		return nil, fmt.Errorf("%w: %d", ErrInvalidKeyLength, length)
+	}
+	_, okm := HKDF(sha256.New, salt, secret, info, length)
+	return okm, nil
+}
+
 // 模拟Echd椭圆取消交换秘钥的过程
-func SimulateEcdh() {
+func SimulateEcdh() error {
 	//---------------------- 服务端Hello -------------------------
 	// 服务端，生成自己的椭圆密钥对
-	privKeyS, pubKeyBytesS, err := GenECDSAKey_secp256r1()
+	privS, pubS, err := GenerateKeyPair(P256)
 	if err != nil {
-		panic(err)
-		return
+		return err
 	}
-
-	// 公司私库，此处将开源库私自改成了EllipticPrivateKey（原版是非导出ellipticPrivateKey）
-	// 为什么要这么改，因为需要在这一步能够jsonencode，然后存入redis
-	//（因为交换逻辑被拆分成了几次接口交互，所以私钥要暂存redis）
-	// privS := privKeyS.(*ecdh.EllipticPrivateKey)
-	// TODO：将privS存入缓存
-	// TODO: 将pubKeyS通过接口返回给客户端
+	// 将privS用服务端的master key密封之后存入缓存
+	masterKey := make([]byte, 32) // 实际使用中从配置或者KMS中读取
+	if _, err := crand.Read(masterKey); err != nil {
+		return err
+	}
+	sealedS, err := SealPrivateKey(privS, JWK, masterKey)
+	if err != nil {
+		return err
+	}
+	// TODO: 将sealedS存入缓存
+	// TODO: 将pubS通过接口返回给客户端
 
 	// ---------------------- 客户端 -------------------------
-	// 客户端，生成自己的椭圆密钥对
-	privKeyD, pubKeyBytesD, err := GenECDSAKey_secp256r1()
+	// 客户端，生成自己的椭圆密钥对，并用服务端的公钥生成shareKey
+	privD, pubD, err := GenerateKeyPair(P256)
 	if err != nil {
-		panic(err)
-		return
+		return err
 	}
-	// 理由同上
-	//privD := privKeyD.(*ecdh.EllipticPrivateKey)
-
-	// 客户端生成shearKey
-	ecdhD := ecdh.NewEllipticECDH(elliptic.P256())
-	publicKeyS, _ := ecdhD.Unmarshal(pubKeyBytesS) // 从参数里面读取出pubS，并解码成为真正的pubS
-	shareKeyD, err := ecdhD.GenerateSharedSecret(privKeyD, publicKeyS)
+	shareKeyD, err := SharedSecret(privD, pubS)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	// TODO 将pubKeyD通过接口请求，传递给服务端
+	// TODO 将pubD通过接口请求，传递给服务端
 
 	//---------------------- 服务端Ecdh -------------------------
-	// 服务端生成shearKey
-	// TODO: 把privS从换成读出来
-	ecdhS := ecdh.NewEllipticECDH(elliptic.P256())
-	publicKeyD, _ := ecdhS.Unmarshal(pubKeyBytesD) // 从参数里面读取出pubD，并解码成为真正的pubD
-	shareKeyS, err := ecdhS.GenerateSharedSecret(privKeyS, publicKeyD)
+	// 服务端生成shareKey
+	// TODO: 把sealedS从缓存读出来
+	privS, err = OpenPrivateKey(sealedS, masterKey)
 	if err != nil {
-		panic(err)
+		return err
+	}
+	shareKeyS, err := SharedSecret(privS, pubD)
+	if err != nil {
+		return err
 	}
 
 	// 完成了交换，生成的ShareKey是一样的
 	fmt.Println("X-------", len(shareKeyD), hex.EncodeToString(shareKeyD))
 	fmt.Println("X-------", len(shareKeyS), hex.EncodeToString(shareKeyS))
-
-	// 原理上来说，共享Key应该是完全一致的
-	if hex.EncodeToString(shareKeyD) != hex.EncodeToString(shareKeyS) {
-		panic("not same")
+	if !bytes.Equal(shareKeyD, shareKeyS) {
+		return errors.New("ecdh: shared secrets mismatch")
 	}
 
 	// 通常，还需要在结合HKDF进行扩展长度扩展，比如这里扩展成为65字节
-	prk, okm := HKDF(sha256.New, []byte(SALT), shareKeyD, []byte(INFO), 65)
-	_ = prk // 第一步抽取的结果
-	if len(okm) != 65 {
-		// 长度不符合预期
-		panic("len != 64")
+	okm, err := DeriveKey(shareKeyD, []byte(SALT), []byte(INFO), 65)
+	if err != nil {
+		return err
 	}
 	fmt.Printf("okm len:%v, detail:%v\n", len(okm), okm)
+	return nil
 }
 
 const (
@@ -117,14 +183,15 @@ func HKDF(h func() hash.Hash, salt, ikm, info []byte, l int) (prk, okm []byte) {
 	hl := len(prk)
 	okm = make([]byte, l, l)
 	f = hmac.New(h, prk)
-	for i := uint8(1); i <= uint8(math.Ceil(float64(l)/float64(hl))); i++ {
-		s := int(i-2) * hl
-		e := int(i-1) * hl
+	// 计数器写入HMAC时只有1个字节，用int计数，避免l恰好是255倍哈希长度时uint8回绕导致死循环
+	for i := 1; i <= int(math.Ceil(float64(l)/float64(hl))); i++ {
+		s := (i - 2) * hl
+		e := (i - 1) * hl
 		if i != 1 {
 			f.Write(okm[s:e])
 		}
 		f.Write(info)
-		f.Write([]byte{i})
+		f.Write([]byte{byte(i)})
 		copy(okm[e:], f.Sum(nil))
 		f.Reset()
 	}
@@ -1,7 +1,83 @@
 package ecdh
 
-import "testing"
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
 
 func TestSimulateEcdh(t *testing.T) {
-	SimulateEcdh()
+	if err := SimulateEcdh(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestSharedSecret(t *testing.T) {
+	for _, curve := range Curves() {
+		privA, pubA, err := GenerateKeyPair(curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		privB, pubB, err := GenerateKeyPair(curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		secretA, err := SharedSecret(privA, pubB)
+		if err != nil {
+			t.Fatal(err)
+		}
+		secretB, err := SharedSecret(privB, pubA)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(secretA, secretB) || len(secretA) != curve.keySize() {
+			t.Errorf("%s: secretA:%x, secretB:%x", curve.Name(), secretA, secretB)
+		}
+
+		keyA, err := DeriveKey(secretA, []byte(SALT), []byte(INFO), 32)
+		if err != nil {
+			t.Fatal(err)
+		}
+		keyB, _ := DeriveKey(secretB, []byte(SALT), []byte(INFO), 32)
+		if !bytes.Equal(keyA, keyB) || len(keyA) != 32 {
+			t.Errorf("keyA:%x, keyB:%x", keyA, keyB)
+		}
+	}
+}
+
+func TestSharedSecretErrors(t *testing.T) {
+	priv, pub, err := GenerateKeyPair(P256)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := GenerateKeyPair(nil); !errors.Is(err, ErrInvalidCurve) {
+		t.Errorf("err:%v", err)
+	}
+
+	// 公钥被篡改、截断，或者不在曲线上
+	bad := append([]byte(nil), pub...)
+	bad[len(bad)-1] ^= 0xff
+	for _, peer := range [][]byte{nil, pub[:10], bad} {
+		if _, err := SharedSecret(priv, peer); !errors.Is(err, ErrInvalidPublicKey) {
+			t.Errorf("err:%v", err)
+		}
+	}
+	// 其他曲线的公钥
+	_, pub384, _ := GenerateKeyPair(P384)
+	if _, err := SharedSecret(priv, pub384); !errors.Is(err, ErrCurveMismatch) {
+		t.Errorf("err:%v", err)
+	}
+	if _, err := SharedSecret(nil, pub); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("err:%v", err)
+	}
+
+	// 最大长度
+	if key, err := DeriveKey([]byte("secret"), nil, nil, 255*32); err != nil || len(key) != 255*32 {
+		t.Errorf("len:%v, err:%v", len(key), err)
+	}
+	for _, l := range []int{0, 255*32 + 1} {
+		if _, err := DeriveKey([]byte("secret"), nil, nil, l); !errors.Is(err, ErrInvalidKeyLength) {
+			t.Errorf("len:%v, err:%v", l, err)
+		}
+	}
 }
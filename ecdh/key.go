@@ -0,0 +1,245 @@
+package ecdh
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	crand "crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/big"
+)
+
+// 多步握手时，服务端的私钥需要在几次接口交互之间暂存（比如redis），这里提供私钥的序列化，
+// 不再需要修改go-ecdh把私钥类型改成导出的
+
+// KeyFormat 私钥的序列化格式
+type KeyFormat byte
+
+const (
+	PKCS8 KeyFormat = iota + 1 // PKCS#8 DER
+	JWK                        // JSON Web Key（RFC 7517），可以直接存入json
+)
+
+func (f KeyFormat) String() string {
+	switch f {
+	case PKCS8:
+		return "pkcs8"
+	case JWK:
+		return "jwk"
+	}
+	return "unknown"
+}
+
+var (
+	// ErrUnsupportedFormat 不支持的序列化格式
+	ErrUnsupportedFormat = errors.New("ecdh: unsupported key format")
+	// ErrSealedKey 密封的私钥无法打开：master key不对，或者数据被篡改
+	ErrSealedKey = errors.New("ecdh: cannot open sealed key")
+)
+
+// MarshalPrivateKey 按format序列化私钥
+// X25519的PKCS#8遵循RFC 8410，JWK遵循RFC 8037（kty为OKP）
+func MarshalPrivateKey(priv *PrivateKey, format KeyFormat) ([]byte, error) {
+	if priv == nil || priv.curve == nil || len(priv.d) == 0 {
+		return nil, ErrInvalidPrivateKey
+	}
+	switch format {
+	case PKCS8:
+		if nc, ok := priv.curve.(*nistCurve); ok {
+			x, y := elliptic.Unmarshal(nc.curve, priv.pub)
+			return x509.MarshalPKCS8PrivateKey(&ecdsa.PrivateKey{
+				PublicKey: ecdsa.PublicKey{Curve: nc.curve, X: x, Y: y},
+				D:         new(big.Int).SetBytes(priv.d),
+			})
+		}
+		d, err := asn1.Marshal(priv.d)
+		if err != nil {
+			return nil, err
+		}
+		return asn1.Marshal(pkcs8{Algo: pkix.AlgorithmIdentifier{Algorithm: oidX25519}, PrivateKey: d})
+	case JWK:
+		k := jwk{Crv: priv.curve.Name(), D: encodeBase64(priv.d)}
+		if nc, ok := priv.curve.(*nistCurve); ok {
+			size := nc.keySize()
+			k.Kty = "EC"
+			k.X = encodeBase64(priv.pub[1 : 1+size])
+			k.Y = encodeBase64(priv.pub[1+size:])
+		} else {
+			k.Kty = "OKP"
+			k.X = encodeBase64(priv.pub)
+		}
+		return json.Marshal(k)
+	}
+	return nil, fmt.Errorf("%w: %d", ErrUnsupportedFormat, format)
+}
+
+// UnmarshalPrivateKey 解析 MarshalPrivateKey 的结果，会校验私钥和公钥是否匹配
+func UnmarshalPrivateKey(data []byte, format KeyFormat) (*PrivateKey, error) {
+	switch format {
+	case PKCS8:
+		// X25519需要自己解析，其他的交给x509
+		var p pkcs8
+		if _, err := asn1.Unmarshal(data, &p); err == nil && p.Algo.Algorithm.Equal(oidX25519) {
+			var d []byte
+			if _, err := asn1.Unmarshal(p.PrivateKey, &d); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+			}
+			return newPrivateKey(X25519, d, nil)
+		}
+		key, err := x509.ParsePKCS8PrivateKey(data)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+		}
+		ek, ok := key.(*ecdsa.PrivateKey)
+		if !ok {
+			return nil, fmt.Errorf("%w: not an EC key: %T", ErrInvalidPrivateKey, key)
+		}
+		curve, err := CurveByName(ek.Curve.Params().Name)
+		if err != nil {
+			return nil, err
+		}
+		return newPrivateKey(curve, ek.D.Bytes(), elliptic.Marshal(ek.Curve, ek.X, ek.Y))
+	case JWK:
+		var k jwk
+		if err := json.Unmarshal(data, &k); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+		}
+		if k.Kty != "EC" && k.Kty != "OKP" {
+			return nil, fmt.Errorf("%w: unsupported kty %q", ErrInvalidPrivateKey, k.Kty)
+		}
+		curve, err := CurveByName(k.Crv)
+		if err != nil {
+			return nil, err
+		}
+		d, err1 := decodeBase64(k.D)
+		x, err2 := decodeBase64(k.X)
+		y, err3 := decodeBase64(k.Y)
+		if err1 != nil || err2 != nil || err3 != nil {
+			return nil, fmt.Errorf("%w: bad base64url value", ErrInvalidPrivateKey)
+		}
+		nc, isNIST := curve.(*nistCurve)
+		switch {
+		case k.Kty == "EC" && isNIST:
+			size := nc.keySize()
+			if len(x) != size || len(y) != size {
+				return nil, fmt.Errorf("%w: bad coordinate length", ErrInvalidPrivateKey)
+			}
+			pub := append([]byte{4}, x...)
+			return newPrivateKey(curve, d, append(pub, y...))
+		case k.Kty == "OKP" && !isNIST:
+			return newPrivateKey(curve, d, x)
+		}
+		return nil, fmt.Errorf("%w: kty %q does not match crv %q", ErrInvalidPrivateKey, k.Kty, k.Crv)
+	}
+	return nil, fmt.Errorf("%w: %d", ErrUnsupportedFormat, format)
+}
+
+// SealPrivateKey 序列化私钥，并用服务端的master key（16、24或32字节，对应AES-128/192/256）做AES-GCM加密，
+// 避免私钥以明文的形式存放在缓存中。格式：| version(1字节) | format(1字节) | nonce(12字节) | 密文 |
+func SealPrivateKey(priv *PrivateKey, format KeyFormat, masterKey []byte) ([]byte, error) {
+	plain, err := MarshalPrivateKey(priv, format)
+	if err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	header := []byte{sealVersion, byte(format)}
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(crand.Reader, nonce); err != nil {
+		return nil, err
+	}
+	out := append(header, nonce...)
+	// 头部作为附加数据参与认证，防止篡改format
+	return aead.Seal(out, nonce, plain, header), nil
+}
+
+// OpenPrivateKey 解密 SealPrivateKey 的结果并解析私钥
+func OpenPrivateKey(sealed, masterKey []byte) (*PrivateKey, error) {
+	aead, err := newAEAD(masterKey)
+	if err != nil {
+		return nil, err
+	}
+	headerSize := 2 + aead.NonceSize()
+	if len(sealed) < headerSize+aead.Overhead() {
+		return nil, fmt.Errorf("%w: too short", ErrSealedKey)
+	}
+	if sealed[0] != sealVersion {
+		return nil, fmt.Errorf("%w: unsupported version %d", ErrSealedKey, sealed[0])
+	}
+	header, nonce := sealed[:2], sealed[2:headerSize]
+	plain, err := aead.Open(nil, nonce, sealed[headerSize:], header)
+	if err != nil {
+		return nil, ErrSealedKey
+	}
+	return UnmarshalPrivateKey(plain, KeyFormat(header[1]))
+}
+
+const sealVersion = 1
+
+func newAEAD(masterKey []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(masterKey)
+	if err != nil {
+		return nil, fmt.Errorf("ecdh: invalid master key: %w", err)
+	}
+	return cipher.NewGCM(block)
+}
+
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y,omitempty"`
+	D   string `json:"d"`
+}
+
+// PKCS#8（RFC 5208），用于X25519
+type pkcs8 struct {
+	Version    int
+	Algo       pkix.AlgorithmIdentifier
+	PrivateKey []byte
+}
+
+var oidX25519 = asn1.ObjectIdentifier{1, 3, 101, 110}
+
+// 校验私钥的长度、范围，以及和公钥是否匹配，pub为nil时不校验公钥
+func newPrivateKey(curve Curve, d, pub []byte) (*PrivateKey, error) {
+	size := curve.keySize()
+	if nc, ok := curve.(*nistCurve); ok {
+		// PKCS#8中的私钥可能去掉了前导0
+		n := new(big.Int).SetBytes(d)
+		if n.Sign() <= 0 || n.Cmp(nc.curve.Params().N) >= 0 {
+			return nil, fmt.Errorf("%w: scalar out of range", ErrInvalidPrivateKey)
+		}
+		d = leftPad(n.Bytes(), size)
+	}
+	if len(d) != size {
+		return nil, fmt.Errorf("%w: bad scalar length %d", ErrInvalidPrivateKey, len(d))
+	}
+	computed, err := curve.publicKey(d)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidPrivateKey, err)
+	}
+	if pub != nil && !bytes.Equal(pub, computed) {
+		return nil, fmt.Errorf("%w: public key mismatch", ErrInvalidPrivateKey)
+	}
+	return &PrivateKey{curve: curve, d: d, pub: computed}, nil
+}
+
+func encodeBase64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func decodeBase64(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(s)
+}
@@ -0,0 +1,104 @@
+package ecdh
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+func TestMarshalPrivateKey(t *testing.T) {
+	for _, curve := range Curves() {
+		priv, pub, err := GenerateKeyPair(curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, peerPub, _ := GenerateKeyPair(curve)
+		want, _ := SharedSecret(priv, peerPub)
+
+		for _, format := range []KeyFormat{PKCS8, JWK} {
+			data, err := MarshalPrivateKey(priv, format)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := UnmarshalPrivateKey(data, format)
+			if err != nil {
+				t.Fatalf("%s %s: %v", curve.Name(), format, err)
+			}
+			secret, _ := SharedSecret(got, peerPub)
+			if !bytes.Equal(got.PublicKey(), pub) || !bytes.Equal(secret, want) {
+				t.Errorf("%s %s: mismatch", curve.Name(), format)
+			}
+		}
+	}
+}
+
+func TestUnmarshalPrivateKeyErrors(t *testing.T) {
+	priv, _, _ := GenerateKeyPair(P256)
+	other, _, _ := GenerateKeyPair(P256)
+
+	// 私钥和公钥对不上
+	var k jwk
+	data, _ := MarshalPrivateKey(priv, JWK)
+	_ = json.Unmarshal(data, &k)
+	otherData, _ := MarshalPrivateKey(other, JWK)
+	var ok jwk
+	_ = json.Unmarshal(otherData, &ok)
+	k.X = ok.X
+	data, _ = json.Marshal(k)
+	if _, err := UnmarshalPrivateKey(data, JWK); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("err:%v", err)
+	}
+
+	for _, data := range [][]byte{nil, []byte("{"), []byte(`{"kty":"RSA"}`), []byte(`{"kty":"OKP","crv":"P-256"}`)} {
+		if _, err := UnmarshalPrivateKey(data, JWK); !errors.Is(err, ErrInvalidPrivateKey) {
+			t.Errorf("data:%s, err:%v", data, err)
+		}
+	}
+	if _, err := UnmarshalPrivateKey([]byte(`{"kty":"EC","crv":"P-999"}`), JWK); !errors.Is(err, ErrUnsupportedCurve) {
+		t.Errorf("err:%v", err)
+	}
+	if _, err := UnmarshalPrivateKey([]byte{0x30, 0x00}, PKCS8); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("err:%v", err)
+	}
+	if _, err := MarshalPrivateKey(priv, KeyFormat(9)); !errors.Is(err, ErrUnsupportedFormat) {
+		t.Errorf("err:%v", err)
+	}
+	if _, err := MarshalPrivateKey(nil, PKCS8); !errors.Is(err, ErrInvalidPrivateKey) {
+		t.Errorf("err:%v", err)
+	}
+}
+
+func TestSealPrivateKey(t *testing.T) {
+	priv, pub, _ := GenerateKeyPair(P256)
+	masterKey := bytes.Repeat([]byte{1}, 32)
+
+	sealed, err := SealPrivateKey(priv, JWK, masterKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bytes.Contains(sealed, []byte(`"kty"`)) {
+		t.Errorf("sealed key is plaintext")
+	}
+	got, err := OpenPrivateKey(sealed, masterKey)
+	if err != nil || !bytes.Equal(got.PublicKey(), pub) {
+		t.Fatalf("err:%v", err)
+	}
+
+	// master key不对
+	if _, err := OpenPrivateKey(sealed, bytes.Repeat([]byte{2}, 32)); !errors.Is(err, ErrSealedKey) {
+		t.Errorf("err:%v", err)
+	}
+	// 篡改format
+	bad := append([]byte(nil), sealed...)
+	bad[1] = byte(PKCS8)
+	if _, err := OpenPrivateKey(bad, masterKey); !errors.Is(err, ErrSealedKey) {
+		t.Errorf("err:%v", err)
+	}
+	if _, err := OpenPrivateKey(sealed[:10], masterKey); !errors.Is(err, ErrSealedKey) {
+		t.Errorf("err:%v", err)
+	}
+	if _, err := SealPrivateKey(priv, PKCS8, []byte("short")); err == nil {
+		t.Errorf("want error")
+	}
+}
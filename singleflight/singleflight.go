@@ -80,7 +80,7 @@ func getDataWithSF(key string) (string, error) {
             log.Println("Call another time")
         }
         data = v.(string)
-        // TODO 种缓存
+        // TODO 种缓存（可复用的完整实现见 bigcache.LoadingCache）
         return data, nil
     }
     return "", errors.New("Never happen here!")